@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/supercakecrumb/nomia/internal/lifetable"
+)
+
+// loadLifeTable parses an SSA period life table and replaces the country's
+// survival rates with it in one transaction
+func loadLifeTable(ctx context.Context, conn *pgx.Conn, countryID int, path string, periodYear int) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	table, err := lifetable.ParseSSAPeriod(file)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DELETE FROM life_tables WHERE country_id = $1`, countryID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO life_tables (country_id, format, period_year, source_file_name)
+		VALUES ($1, $2, $3, $4)
+	`, countryID, lifetable.FormatSSAPeriod, periodYear, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+
+	var rows [][]interface{}
+	for age := range table.Female {
+		rows = append(rows,
+			[]interface{}{countryID, "F", age, table.Female[age]},
+			[]interface{}{countryID, "M", age, table.Male[age]},
+		)
+	}
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"survival_rates"},
+		[]string{"country_id", "sex", "age", "survivors"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -1,18 +1,16 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/supercakecrumb/nomia/internal/estimator"
+	"github.com/supercakecrumb/nomia/internal/ssa"
 )
 
 // Command-line flags
@@ -23,6 +21,10 @@ var (
 	yearTo      = flag.Int("year-to", 0, "End year (optional, 0 means all)")
 	dryRun      = flag.Bool("dry-run", false, "Validate files without importing")
 	verbose     = flag.Bool("verbose", false, "Verbose output")
+	modelPath   = flag.String("estimator-model", "estimator-model.json", "Where to write the gender estimator model (empty to skip training)")
+	lifeTable   = flag.String("life-table", "", "SSA period life table file to load for the country (optional)")
+	lifeYear    = flag.Int("life-table-year", 0, "Calendar year the life table describes (required with -life-table)")
+	remove      = flag.Bool("remove", false, "Remove the country's datasets in the year range instead of importing")
 )
 
 type NameRecord struct {
@@ -51,6 +53,8 @@ func main() {
 	}
 	if *dryRun {
 		fmt.Println("Mode: DRY RUN (validation only)")
+	} else if *remove {
+		fmt.Println("Mode: REMOVE")
 	}
 	if *verbose {
 		fmt.Println("Verbose: ON")
@@ -88,6 +92,35 @@ func main() {
 		fmt.Printf("✅ %s country ID: %d\n", *countryCode, countryID)
 	}
 
+	// Remove datasets and refresh everything derived from them
+	if *remove && !*dryRun {
+		years, err := removeDatasets(ctx, conn, countryID, *yearFrom, *yearTo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to remove datasets: %v\n", err)
+			os.Exit(1)
+		}
+		if len(years) > 0 {
+			if err := refreshTrajectories(ctx, conn); err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Failed to refresh name trajectories: %v\n", err)
+			}
+		}
+		fmt.Printf("\n🎉 Removal complete! Removed %d years of %s data\n", len(years), *countryCode)
+		return
+	}
+
+	// Load the survival table before the names, it does not depend on them
+	if *lifeTable != "" && !*dryRun {
+		if *lifeYear == 0 {
+			fmt.Fprintf(os.Stderr, "❌ -life-table-year is required with -life-table\n")
+			os.Exit(1)
+		}
+		if err := loadLifeTable(ctx, conn, countryID, *lifeTable, *lifeYear); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to load life table: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Loaded %d life table from %s\n", *lifeYear, filepath.Base(*lifeTable))
+	}
+
 	// Find all data files in specified directory
 	var filePattern string
 	switch *countryCode {
@@ -131,7 +164,7 @@ func main() {
 		}
 
 		// Extract year from filename
-		year, err := extractYearFromFilename(filepath.Base(filePath))
+		year, err := ssa.FileYear(filepath.Base(filePath))
 		if err != nil {
 			if *verbose {
 				fmt.Fprintf(os.Stderr, "⚠️  Skipping file %s: %v\n", filePath, err)
@@ -177,37 +210,31 @@ func main() {
 			continue
 		}
 
-		// Create dataset record
-		datasetID, err := insertDataset(ctx, conn, countryID, year, filepath.Base(filePath), filePath)
+		// Create the dataset and everything derived from it
+		count, err := importFile(ctx, conn, countryID, year, filePath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "\n❌ Failed to create dataset: %v\n", err)
+			fmt.Fprintf(os.Stderr, "\n❌ Failed to import %s: %v\n", filepath.Base(filePath), err)
 			continue
 		}
-		if *verbose {
-			fmt.Printf("✅ Created dataset ID: %d for year %d\n", datasetID, year)
-		}
 
-		// Parse file
-		records, err := parseSSAFile(filePath, year, countryID, datasetID)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "\n❌ Failed to parse file: %v\n", err)
-			continue
-		}
+		totalRecords += count
+		filesProcessed++
 		if *verbose {
-			fmt.Printf("📊 Parsed %d records\n", len(records))
+			fmt.Printf("✅ Imported %d records for year %d\n", count, year)
 		}
+	}
 
-		// Batch insert records
-		err = batchInsertNames(ctx, conn, records)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "\n❌ Failed to insert records: %v\n", err)
-			continue
+	// Rebuild trajectory vectors once all years are in
+	if !*dryRun && filesProcessed > 0 {
+		if err := refreshTrajectories(ctx, conn); err != nil {
+			fmt.Fprintf(os.Stderr, "\n❌ Failed to refresh name trajectories: %v\n", err)
 		}
-
-		totalRecords += len(records)
-		filesProcessed++
-		if *verbose {
-			fmt.Printf("✅ Imported %d records for year %d\n", len(records), year)
+		if *modelPath != "" {
+			if err := trainEstimator(ctx, conn, *modelPath); err != nil {
+				fmt.Fprintf(os.Stderr, "\n❌ Failed to train gender estimator: %v\n", err)
+			} else if *verbose {
+				fmt.Printf("\n✅ Wrote gender estimator model to %s\n", *modelPath)
+			}
 		}
 	}
 
@@ -233,22 +260,6 @@ func ensureCountry(ctx context.Context, conn *pgx.Conn, code string) (int, error
 	return countryID, nil
 }
 
-// extractYearFromFilename extracts the year from SSA filename format (yob2023.txt)
-func extractYearFromFilename(filename string) (int, error) {
-	re := regexp.MustCompile(`yob(\d{4})\.txt`)
-	matches := re.FindStringSubmatch(filename)
-	if len(matches) != 2 {
-		return 0, fmt.Errorf("filename does not match expected format 'yobYYYY.txt'")
-	}
-
-	year, err := strconv.Atoi(matches[1])
-	if err != nil {
-		return 0, fmt.Errorf("invalid year in filename: %v", err)
-	}
-
-	return year, nil
-}
-
 // datasetExists checks if a dataset for the given year already exists
 func datasetExists(ctx context.Context, conn *pgx.Conn, countryID, year int, filename string) (bool, error) {
 	var count int
@@ -264,10 +275,64 @@ func datasetExists(ctx context.Context, conn *pgx.Conn, countryID, year int, fil
 	return count > 0, nil
 }
 
+// importFile imports one SSA file as a dataset in a single transaction that
+// also refreshes the births totals, ranks and aggregates of its year and
+// bumps the data version. A failed step leaves no dataset behind, so the
+// next run imports the file again instead of skipping it. Returns the
+// number of imported records.
+func importFile(ctx context.Context, conn *pgx.Conn, countryID, year int, filePath string) (int, error) {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Create dataset record
+	datasetID, err := insertDataset(ctx, tx, countryID, year, filepath.Base(filePath), filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create dataset: %w", err)
+	}
+	if *verbose {
+		fmt.Printf("✅ Created dataset ID: %d for year %d\n", datasetID, year)
+	}
+
+	// Parse file
+	records, err := parseSSAFile(filePath, year, countryID, datasetID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse file: %w", err)
+	}
+	if *verbose {
+		fmt.Printf("📊 Parsed %d records\n", len(records))
+	}
+
+	// Batch insert records
+	if err := batchInsertNames(ctx, tx, records); err != nil {
+		return 0, fmt.Errorf("failed to insert records: %w", err)
+	}
+
+	// Refresh births totals used for normalized popularity
+	if err := refreshBirthTotals(ctx, tx, countryID, year); err != nil {
+		return 0, fmt.Errorf("failed to refresh births totals: %w", err)
+	}
+	if err := refreshYearRanks(ctx, tx, year); err != nil {
+		return 0, fmt.Errorf("failed to refresh year ranks: %w", err)
+	}
+	if err := refreshAggregates(ctx, tx, countryID, year); err != nil {
+		return 0, fmt.Errorf("failed to refresh name aggregates: %w", err)
+	}
+
+	// Servers drop cached results once the year is committed
+	if err := bumpDataVersion(ctx, tx); err != nil {
+		return 0, fmt.Errorf("failed to bump data version: %w", err)
+	}
+
+	return len(records), tx.Commit(ctx)
+}
+
 // insertDataset creates a dataset record and returns its ID
-func insertDataset(ctx context.Context, conn *pgx.Conn, countryID, year int, filename, storagePath string) (int, error) {
+func insertDataset(ctx context.Context, tx pgx.Tx, countryID, year int, filename, storagePath string) (int, error) {
 	var datasetID int
-	err := conn.QueryRow(ctx, `
+	err := tx.QueryRow(ctx, `
 		INSERT INTO name_datasets (
 			country_id, 
 			source_file_name, 
@@ -276,84 +341,130 @@ func insertDataset(ctx context.Context, conn *pgx.Conn, countryID, year int, fil
 			file_type, 
 			storage_path,
 			parse_status,
+			suppression_threshold,
 			uploaded_at,
 			parsed_at
-		) VALUES ($1, $2, $3, $3, 'SSA-TXT', $4, 'parsed', NOW(), NOW())
+		) VALUES ($1, $2, $3, $3, 'SSA-TXT', $4, 'parsed', $5, NOW(), NOW())
 		RETURNING id
-	`, countryID, filename, year, storagePath).Scan(&datasetID)
+	`, countryID, filename, year, storagePath, ssa.SuppressionThreshold).Scan(&datasetID)
 
 	return datasetID, err
 }
 
 // parseSSAFile parses an SSA format file (name,gender,count) and returns records
 func parseSSAFile(path string, year, countryID, datasetID int) ([]NameRecord, error) {
-	file, err := os.Open(path)
+	parsed, err := ssa.ParseFile(path)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
-
-	var records []NameRecord
-	scanner := bufio.NewScanner(file)
-	lineNum := 0
 
-	for scanner.Scan() {
-		lineNum++
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
+	records := make([]NameRecord, len(parsed))
+	for i, r := range parsed {
+		records[i] = NameRecord{
+			Year:      year,
+			Name:      r.Name,
+			Gender:    r.Gender,
+			Count:     r.Count,
+			CountryID: countryID,
+			DatasetID: datasetID,
 		}
+	}
 
-		// Parse CSV: name,gender,count
-		parts := strings.Split(line, ",")
-		if len(parts) != 3 {
-			return nil, fmt.Errorf("invalid format at line %d: expected 3 fields, got %d", lineNum, len(parts))
-		}
+	return records, nil
+}
 
-		name := strings.TrimSpace(parts[0])
-		gender := strings.TrimSpace(parts[1])
-		countStr := strings.TrimSpace(parts[2])
+// refreshBirthTotals recomputes the births totals for a country and year
+// from the imported name counts
+func refreshBirthTotals(ctx context.Context, tx pgx.Tx, countryID, year int) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO country_year_births (country_id, year, total_births, female_births, male_births, updated_at)
+		SELECT
+			country_id,
+			year,
+			SUM(count),
+			SUM(CASE WHEN gender = 'F' THEN count ELSE 0 END),
+			SUM(CASE WHEN gender = 'M' THEN count ELSE 0 END),
+			NOW()
+		FROM names
+		WHERE country_id = $1 AND year = $2
+		GROUP BY country_id, year
+		ON CONFLICT (country_id, year) DO UPDATE SET
+			total_births = EXCLUDED.total_births,
+			female_births = EXCLUDED.female_births,
+			male_births = EXCLUDED.male_births,
+			updated_at = EXCLUDED.updated_at
+	`, countryID, year)
+
+	return err
+}
 
-		// Validate gender
-		if gender != "F" && gender != "M" {
-			return nil, fmt.Errorf("invalid gender at line %d: %s (expected F or M)", lineNum, gender)
-		}
+// refreshYearRanks recomputes the per-year name ranks for every country and
+// the pooled scope
+func refreshYearRanks(ctx context.Context, tx pgx.Tx, year int) error {
+	_, err := tx.Exec(ctx, `SELECT refresh_name_year_ranks($1)`, year)
+	return err
+}
 
-		// Parse count
-		count, err := strconv.Atoi(countStr)
-		if err != nil {
-			return nil, fmt.Errorf("invalid count at line %d: %v", lineNum, err)
-		}
+// refreshTrajectories rebuilds the decade vectors used by the similar
+// trajectory search and bumps the data version with them: they are rebuilt
+// after the per-file bumps, and results cached against the old vectors must
+// not stay valid
+func refreshTrajectories(ctx context.Context, conn *pgx.Conn) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
 
-		if count <= 0 {
-			return nil, fmt.Errorf("invalid count at line %d: must be positive", lineNum)
-		}
+	if _, err := tx.Exec(ctx, `SELECT refresh_name_trajectories()`); err != nil {
+		return err
+	}
+	if err := bumpDataVersion(ctx, tx); err != nil {
+		return fmt.Errorf("failed to bump data version: %w", err)
+	}
 
-		records = append(records, NameRecord{
-			Year:      year,
-			Name:      name,
-			Gender:    gender,
-			Count:     count,
-			CountryID: countryID,
-			DatasetID: datasetID,
-		})
+	return tx.Commit(ctx)
+}
+
+// trainEstimator trains the gender estimator on every name in the database
+// (all countries and years pooled) and writes it to path
+func trainEstimator(ctx context.Context, conn *pgx.Conn, path string) error {
+	rows, err := conn.Query(ctx, `
+		SELECT
+			name,
+			SUM(CASE WHEN gender = 'F' THEN count ELSE 0 END),
+			SUM(CASE WHEN gender = 'M' THEN count ELSE 0 END)
+		FROM names
+		GROUP BY name
+	`)
+	if err != nil {
+		return err
 	}
+	defer rows.Close()
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	var examples []estimator.Example
+	for rows.Next() {
+		var ex estimator.Example
+		if err := rows.Scan(&ex.Name, &ex.FemaleCount, &ex.MaleCount); err != nil {
+			return err
+		}
+		examples = append(examples, ex)
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
 
-	return records, nil
+	return estimator.Train(examples).Save(path)
 }
 
 // batchInsertNames efficiently inserts records using pgx.CopyFrom
-func batchInsertNames(ctx context.Context, conn *pgx.Conn, records []NameRecord) error {
+func batchInsertNames(ctx context.Context, tx pgx.Tx, records []NameRecord) error {
 	if len(records) == 0 {
 		return nil
 	}
 
 	// Use COPY for maximum performance
-	copyCount, err := conn.CopyFrom(
+	copyCount, err := tx.CopyFrom(
 		ctx,
 		pgx.Identifier{"names"},
 		[]string{"country_id", "dataset_id", "year", "name", "gender", "count"},
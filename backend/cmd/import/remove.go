@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// removeDatasets deletes the country's datasets and name rows in the year
+// range (0 = unbounded), refreshes the ranks and aggregates of the affected
+// years and bumps the data version in one transaction, and returns the
+// affected years
+func removeDatasets(ctx context.Context, conn *pgx.Conn, countryID, yearFrom, yearTo int) ([]int, error) {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT year_from
+		FROM name_datasets
+		WHERE country_id = $1
+		  AND ($2 = 0 OR year_from >= $2)
+		  AND ($3 = 0 OR year_from <= $3)
+		ORDER BY year_from
+	`, countryID, yearFrom, yearTo)
+	if err != nil {
+		return nil, err
+	}
+
+	var years []int
+	for rows.Next() {
+		var year int
+		if err := rows.Scan(&year); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		years = append(years, year)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, query := range []string{
+		`DELETE FROM names WHERE country_id = $1 AND year = ANY($2)`,
+		`DELETE FROM name_datasets WHERE country_id = $1 AND year_from = ANY($2)`,
+		`DELETE FROM country_year_births WHERE country_id = $1 AND year = ANY($2)`,
+	} {
+		if _, err := tx.Exec(ctx, query, countryID, years); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, year := range years {
+		if err := refreshYearRanks(ctx, tx, year); err != nil {
+			return nil, fmt.Errorf("failed to refresh year ranks for %d: %w", year, err)
+		}
+		if err := refreshAggregates(ctx, tx, countryID, year); err != nil {
+			return nil, fmt.Errorf("failed to refresh name aggregates for %d: %w", year, err)
+		}
+	}
+	if len(years) > 0 {
+		if err := bumpDataVersion(ctx, tx); err != nil {
+			return nil, fmt.Errorf("failed to bump data version: %w", err)
+		}
+	}
+
+	return years, tx.Commit(ctx)
+}
+
+// bumpDataVersion marks the data as changed so servers drop cached results
+func bumpDataVersion(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, `SELECT bump_data_version()`)
+	return err
+}
+
+// refreshAggregates rebuilds the name rollups of a country and year and the
+// whole-range stats of the names they touch
+func refreshAggregates(ctx context.Context, tx pgx.Tx, countryID, year int) error {
+	_, err := tx.Exec(ctx, `SELECT refresh_name_aggregates($1, $2)`, countryID, year)
+	return err
+}
@@ -10,8 +10,12 @@ import (
 	"github.com/go-chi/cors"
 	"github.com/supercakecrumb/nomia/internal/config"
 	"github.com/supercakecrumb/nomia/internal/db"
+	"github.com/supercakecrumb/nomia/internal/estimator"
 	"github.com/supercakecrumb/nomia/internal/handlers"
+	"github.com/supercakecrumb/nomia/internal/memstore"
 	"github.com/supercakecrumb/nomia/internal/middleware"
+	"github.com/supercakecrumb/nomia/internal/openapi"
+	"github.com/supercakecrumb/nomia/internal/snapshot"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -70,16 +74,48 @@ func main() {
 		os.Exit(1)
 	}
 	defer logger.Sync()
+	cfg.Logger = logger
 
-	// 3. Initialize database connection
-	if !cfg.FixtureMode {
+	// 3. Initialize database connection, or load the data to serve from
+	if cfg.FixtureMode {
+		store := memstore.New(memstore.UnitedStates)
+		files, err := store.LoadSSADir("US", cfg.FixtureDir)
+		if err != nil {
+			logger.Fatal("Failed to load fixture data", zap.String("dir", cfg.FixtureDir), zap.Error(err))
+		}
+		cfg.DB = store
+		logger.Info("Serving fixture data from memory", zap.String("dir", cfg.FixtureDir), zap.Int("files", files))
+	} else if cfg.SnapshotPath != "" {
+		reader, err := snapshot.Open(cfg.SnapshotPath)
+		if err != nil {
+			logger.Fatal("Failed to open snapshot", zap.String("path", cfg.SnapshotPath), zap.Error(err))
+		}
+		defer reader.Close()
+		cfg.DB = reader
+		logger.Info("Serving read-only from snapshot", zap.String("path", cfg.SnapshotPath))
+	} else {
 		database, err := db.New(cfg.DatabaseURL)
 		if err != nil {
 			logger.Fatal("Failed to connect to database", zap.Error(err))
 		}
 		defer database.Close()
+		if cfg.CacheSize > 0 {
+			database.Cache = db.NewQueryCache(cfg.CacheSize, cfg.CacheTTL)
+		}
 		cfg.DB = database
-		logger.Info("Database connected successfully")
+		logger.Info("Database connected successfully",
+			zap.Int("cache_size", cfg.CacheSize),
+			zap.Duration("cache_ttl", cfg.CacheTTL),
+		)
+	}
+
+	// Load the gender estimator model; /api/names/estimate is unavailable without it
+	model, err := estimator.Load(cfg.EstimatorModelPath)
+	if err != nil {
+		logger.Warn("Gender estimator model not loaded", zap.String("path", cfg.EstimatorModelPath), zap.Error(err))
+	} else {
+		cfg.Estimator = model
+		logger.Info("Gender estimator model loaded", zap.Int("names", model.Names))
 	}
 
 	// 4. Create chi router
@@ -93,15 +129,28 @@ func main() {
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
 		AllowCredentials: true,
 	}))
+	if cfg.OpenAPIValidate {
+		// Test mode: requests and responses must match the OpenAPI spec. It
+		// wraps ConditionalGET so 304 responses are checked too.
+		doc, err := openapi.Load()
+		if err != nil {
+			logger.Fatal("Failed to load OpenAPI spec", zap.Error(err))
+		}
+		validator, err := openapi.Validator(doc)
+		if err != nil {
+			logger.Fatal("Failed to create OpenAPI validator", zap.Error(err))
+		}
+		r.Use(validator)
+		logger.Warn("Validating requests and responses against the OpenAPI spec")
+	}
+	r.Use(middleware.ConditionalGET(handlers.DataVersion(cfg), []middleware.CacheRule{
+		// Years and countries only change on import; ETags revalidate them after
+		{Prefix: "/api/meta/", CacheControl: "public, max-age=86400"},
+		{Prefix: "/api/", CacheControl: "public, max-age=300"},
+	}))
 
 	// 6. Register routes
-	r.Get("/api/meta/years", handlers.MetaYears(cfg))
-	r.Get("/api/meta/countries", handlers.MetaCountries(cfg))
-	r.Get("/api/names", handlers.NamesList(cfg))
-	r.Get("/api/names/trend", handlers.NameTrend(cfg))
-
-	// Health check endpoint
-	r.Get("/health", handlers.Health(cfg))
+	handlers.Routes(r, cfg)
 
 	// Log startup information
 	logger.Info("Server starting",
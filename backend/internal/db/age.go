@@ -0,0 +1,302 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/supercakecrumb/nomia/internal/lifetable"
+)
+
+// BirthCell is the births of a name in one country, year and sex
+type BirthCell struct {
+	CountryCode string
+	Year        int
+	Gender      string
+	Count       int
+}
+
+type AgeBin struct {
+	AgeFrom int     `json:"age_from"`
+	AgeTo   int     `json:"age_to"`
+	Living  float64 `json:"living"`
+	Female  float64 `json:"female"`
+	Male    float64 `json:"male"`
+}
+
+type LifeTableInfo struct {
+	CountryCode string `json:"country_code"`
+	Format      string `json:"format"`
+	PeriodYear  int    `json:"period_year"`
+	SourceFile  string `json:"source_file"`
+}
+
+// AgeDistribution is the estimated age structure of living bearers
+type AgeDistribution struct {
+	EstimatedLiving       float64  `json:"estimated_living"`
+	EstimatedLivingFemale float64  `json:"estimated_living_female"`
+	EstimatedLivingMale   float64  `json:"estimated_living_male"`
+	MedianAge             int      `json:"median_age"`
+	Histogram             []AgeBin `json:"histogram"`
+
+	// Countries with births for the name but no life table; their births
+	// are left out of every estimate
+	CountriesWithoutTable []string `json:"countries_without_table"`
+}
+
+type AgeDistributionMeta struct {
+	AsOf       int             `json:"as_of"`
+	BinWidth   int             `json:"bin_width"`
+	LifeTables []LifeTableInfo `json:"life_tables"`
+}
+
+type AgeDistributionResponse struct {
+	Name        string              `json:"name"`
+	Meta        AgeDistributionMeta `json:"meta"`
+	TotalBirths int                 `json:"total_births"` // all recorded births up to as_of
+	AgeDistribution
+}
+
+type AgeDistributionParams struct {
+	Name      string
+	Countries []string
+	AsOf      int // reference year ages are computed at
+	BinWidth  int // histogram bin width in years
+}
+
+func ParseAgeDistributionParams(query url.Values, dbStart, dbEnd int) (*AgeDistributionParams, error) {
+	params := &AgeDistributionParams{
+		// Defaults
+		Countries: []string{}, // empty = all countries
+		AsOf:      dbEnd,
+		BinWidth:  10,
+	}
+
+	// Parse name (required)
+	params.Name = query.Get("name")
+	if params.Name == "" {
+		return nil, fmt.Errorf("name parameter is required")
+	}
+
+	// Parse countries (comma-separated)
+	if v := query.Get("countries"); v != "" {
+		params.Countries = strings.Split(v, ",")
+	}
+
+	// Parse as_of
+	if v := query.Get("as_of"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("as_of must be an integer")
+		}
+		params.AsOf = val
+	}
+
+	// Parse bin_width
+	if v := query.Get("bin_width"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("bin_width must be an integer")
+		}
+		params.BinWidth = val
+	}
+
+	// Validate
+	if params.AsOf < dbStart || params.AsOf > dbEnd+50 {
+		return nil, fmt.Errorf("as_of must be between %d and %d", dbStart, dbEnd+50)
+	}
+	if params.BinWidth < 1 || params.BinWidth > 25 {
+		return nil, fmt.Errorf("bin_width must be between 1 and 25")
+	}
+
+	return params, nil
+}
+
+// ComputeAgeDistribution applies period survival to each birth cell: of
+// Count births in Year, Count * l(asOf - Year) / Radix are assumed alive in
+// asOf. Migration is ignored.
+func ComputeAgeDistribution(cells []BirthCell, tables map[string]*lifetable.Table, asOf, binWidth int) AgeDistribution {
+	var female, male []float64
+	missing := make(map[string]bool)
+
+	for _, cell := range cells {
+		table, ok := tables[cell.CountryCode]
+		if !ok {
+			missing[cell.CountryCode] = true
+			continue
+		}
+		age := asOf - cell.Year
+		if age < 0 {
+			continue
+		}
+
+		var survivors []float64
+		var living *[]float64
+		switch cell.Gender {
+		case "F":
+			survivors, living = table.Female, &female
+		case "M":
+			survivors, living = table.Male, &male
+		default:
+			continue
+		}
+		if age >= len(survivors) {
+			continue
+		}
+
+		for len(*living) <= age {
+			*living = append(*living, 0)
+		}
+		(*living)[age] += float64(cell.Count) * survivors[age] / lifetable.Radix
+	}
+
+	dist := AgeDistribution{
+		Histogram:             []AgeBin{},
+		CountriesWithoutTable: []string{},
+	}
+	for code := range missing {
+		dist.CountriesWithoutTable = append(dist.CountriesWithoutTable, code)
+	}
+	sort.Strings(dist.CountriesWithoutTable)
+
+	maxAge := max(len(female), len(male))
+	byAge := make([]float64, maxAge)
+	for age := 0; age < maxAge; age++ {
+		if age < len(female) {
+			byAge[age] += female[age]
+			dist.EstimatedLivingFemale += female[age]
+		}
+		if age < len(male) {
+			byAge[age] += male[age]
+			dist.EstimatedLivingMale += male[age]
+		}
+	}
+	dist.EstimatedLiving = dist.EstimatedLivingFemale + dist.EstimatedLivingMale
+	if dist.EstimatedLiving == 0 {
+		return dist
+	}
+
+	// Median: youngest age by which half the living bearers are counted
+	var cumulative float64
+	for age, living := range byAge {
+		cumulative += living
+		if cumulative >= dist.EstimatedLiving/2 {
+			dist.MedianAge = age
+			break
+		}
+	}
+
+	for from := 0; from < maxAge; from += binWidth {
+		bin := AgeBin{AgeFrom: from, AgeTo: from + binWidth - 1}
+		for age := from; age <= bin.AgeTo && age < maxAge; age++ {
+			if age < len(female) {
+				bin.Female += female[age]
+			}
+			if age < len(male) {
+				bin.Male += male[age]
+			}
+		}
+		bin.Living = bin.Female + bin.Male
+		dist.Histogram = append(dist.Histogram, bin)
+	}
+
+	return dist
+}
+
+func (db *DB) GetAgeDistribution(ctx context.Context, params *AgeDistributionParams) (*AgeDistributionResponse, error) {
+	var countries interface{}
+	if len(params.Countries) == 0 {
+		countries = nil
+	} else {
+		countries = params.Countries
+	}
+
+	// Query 1: Births of the name per country, year and sex
+	birthsQuery := `
+		SELECT c.code, n.year, n.gender, SUM(n.count)
+		FROM names n
+		JOIN countries c ON n.country_id = c.id
+		WHERE n.name ILIKE $1
+		  AND n.year <= $2
+		  AND ($3::text[] IS NULL OR c.code = ANY($3::text[]))
+		GROUP BY c.code, n.year, n.gender
+	`
+
+	rows, err := db.Pool.Query(ctx, birthsQuery, params.Name, params.AsOf, countries)
+	if err != nil {
+		return nil, fmt.Errorf("births query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var cells []BirthCell
+	var totalBirths int
+	for rows.Next() {
+		var cell BirthCell
+		if err := rows.Scan(&cell.CountryCode, &cell.Year, &cell.Gender, &cell.Count); err != nil {
+			return nil, fmt.Errorf("births scan failed: %w", err)
+		}
+		cells = append(cells, cell)
+		totalBirths += cell.Count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("births rows failed: %w", err)
+	}
+
+	// Query 2: Life tables of the selected countries
+	tablesQuery := `
+		SELECT c.code, lt.format, lt.period_year, lt.source_file_name, sr.sex, sr.age, sr.survivors
+		FROM life_tables lt
+		JOIN countries c ON lt.country_id = c.id
+		JOIN survival_rates sr ON sr.country_id = lt.country_id
+		WHERE ($1::text[] IS NULL OR c.code = ANY($1::text[]))
+		ORDER BY c.code, sr.sex, sr.age
+	`
+
+	rows, err = db.Pool.Query(ctx, tablesQuery, countries)
+	if err != nil {
+		return nil, fmt.Errorf("life table query failed: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make(map[string]*lifetable.Table)
+	lifeTables := []LifeTableInfo{}
+	for rows.Next() {
+		var info LifeTableInfo
+		var sex string
+		var age int
+		var survivors float64
+		if err := rows.Scan(&info.CountryCode, &info.Format, &info.PeriodYear, &info.SourceFile, &sex, &age, &survivors); err != nil {
+			return nil, fmt.Errorf("life table scan failed: %w", err)
+		}
+
+		table, ok := tables[info.CountryCode]
+		if !ok {
+			table = &lifetable.Table{}
+			tables[info.CountryCode] = table
+			lifeTables = append(lifeTables, info)
+		}
+		// Rows are ordered by age, so appending keeps the index equal to the age
+		if sex == "F" {
+			table.Female = append(table.Female, survivors)
+		} else {
+			table.Male = append(table.Male, survivors)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("life table rows failed: %w", err)
+	}
+
+	return &AgeDistributionResponse{
+		Name: params.Name,
+		Meta: AgeDistributionMeta{
+			AsOf:       params.AsOf,
+			BinWidth:   params.BinWidth,
+			LifeTables: lifeTables,
+		},
+		TotalBirths:     totalBirths,
+		AgeDistribution: ComputeAgeDistribution(cells, tables, params.AsOf, params.BinWidth),
+	}, nil
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"math"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/supercakecrumb/nomia/internal/lifetable"
+)
+
+func TestParseAgeDistributionParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   url.Values
+		wantErr string
+	}{
+		{"defaults", url.Values{"name": []string{"Linda"}}, ""},
+		{"missing name", url.Values{}, "name parameter is required"},
+		{"as_of before data", url.Values{"name": []string{"Linda"}, "as_of": []string{"1800"}}, "as_of must be between 1880 and 2074"},
+		{"bin_width out of range", url.Values{"name": []string{"Linda"}, "bin_width": []string{"0"}}, "bin_width must be between 1 and 25"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := ParseAgeDistributionParams(tt.query, 1880, 2024)
+			if tt.wantErr != "" {
+				if err == nil || !contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseAgeDistributionParams() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAgeDistributionParams() unexpected error = %v", err)
+			}
+			if params.AsOf != 2024 || params.BinWidth != 10 {
+				t.Errorf("defaults = as_of %d bin_width %d, want 2024 and 10", params.AsOf, params.BinWidth)
+			}
+		})
+	}
+}
+
+func TestComputeAgeDistribution(t *testing.T) {
+	tables := map[string]*lifetable.Table{
+		"US": {
+			Female: []float64{100000, 90000, 50000},
+			Male:   []float64{100000, 80000, 40000},
+		},
+	}
+	cells := []BirthCell{
+		{CountryCode: "US", Year: 2024, Gender: "F", Count: 100}, // age 0: 100 alive
+		{CountryCode: "US", Year: 2023, Gender: "M", Count: 100}, // age 1: 80 alive
+		{CountryCode: "US", Year: 2022, Gender: "F", Count: 100}, // age 2: 50 alive
+		{CountryCode: "US", Year: 2000, Gender: "M", Count: 100}, // beyond the table
+		{CountryCode: "UK", Year: 2024, Gender: "F", Count: 100}, // no table
+	}
+
+	dist := ComputeAgeDistribution(cells, tables, 2024, 2)
+
+	if math.Abs(dist.EstimatedLiving-230) > 1e-9 || math.Abs(dist.EstimatedLivingFemale-150) > 1e-9 || math.Abs(dist.EstimatedLivingMale-80) > 1e-9 {
+		t.Errorf("living = %v (F %v, M %v), want 230 (F 150, M 80)", dist.EstimatedLiving, dist.EstimatedLivingFemale, dist.EstimatedLivingMale)
+	}
+	if dist.MedianAge != 1 {
+		t.Errorf("MedianAge = %d, want 1", dist.MedianAge)
+	}
+	want := []AgeBin{
+		{AgeFrom: 0, AgeTo: 1, Living: 180, Female: 100, Male: 80},
+		{AgeFrom: 2, AgeTo: 3, Living: 50, Female: 50},
+	}
+	if !reflect.DeepEqual(dist.Histogram, want) {
+		t.Errorf("Histogram = %+v, want %+v", dist.Histogram, want)
+	}
+	if !reflect.DeepEqual(dist.CountriesWithoutTable, []string{"UK"}) {
+		t.Errorf("CountriesWithoutTable = %v, want [UK]", dist.CountriesWithoutTable)
+	}
+}
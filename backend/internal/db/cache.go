@@ -0,0 +1,281 @@
+package db
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Query cache defaults
+const (
+	DefaultCacheSize = 1000
+	DefaultCacheTTL  = 10 * time.Minute
+
+	// How often the data version is re-read; results may be stale for at
+	// most this long after an import or removal
+	versionCheckInterval = 2 * time.Second
+)
+
+// CacheStats reports query cache usage
+type CacheStats struct {
+	Hits        int64 `json:"hits"`
+	Misses      int64 `json:"misses"`
+	Evictions   int64 `json:"evictions"`
+	Entries     int   `json:"entries"`
+	DataVersion int64 `json:"data_version"`
+}
+
+type cacheEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+// QueryCache is an LRU cache of query results with a per-entry TTL. All
+// entries are dropped when the data version changes.
+type QueryCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	now      func() time.Time
+
+	entries map[string]*list.Element
+	order   *list.List // most recently used first
+
+	version          int64
+	versionCheckedAt time.Time
+
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+// NewQueryCache creates a cache holding at most capacity results for ttl each
+func NewQueryCache(capacity int, ttl time.Duration) *QueryCache {
+	return &QueryCache{
+		capacity: capacity,
+		ttl:      ttl,
+		now:      time.Now,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns the cached value for key if it is present and not expired
+func (c *QueryCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	entry := el.Value.(*cacheEntry)
+	if c.now().After(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		c.misses++
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	c.hits++
+	return entry.value, true
+}
+
+// Set stores value under key, evicting the least recently used entry when
+// the cache is full
+func (c *QueryCache) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		entry.value = value
+		entry.expires = expires
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+		c.evictions++
+	}
+}
+
+// SetVersion records the current data version and drops every entry when it
+// differs from the previous one
+func (c *QueryCache) SetVersion(version int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.versionCheckedAt = c.now()
+	if version == c.version {
+		return
+	}
+
+	c.version = version
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// Version returns the last recorded data version and whether it is due for
+// a re-check
+func (c *QueryCache) Version() (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stale := c.version == 0 || c.now().Sub(c.versionCheckedAt) >= versionCheckInterval
+	return c.version, stale
+}
+
+// Stats returns the cache counters
+func (c *QueryCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Hits:        c.hits,
+		Misses:      c.misses,
+		Evictions:   c.evictions,
+		Entries:     c.order.Len(),
+		DataVersion: c.version,
+	}
+}
+
+// CacheStats returns the query cache counters, or nil when caching is off
+func (db *DB) CacheStats() *CacheStats {
+	if db.Cache == nil {
+		return nil
+	}
+	stats := db.Cache.Stats()
+	return &stats
+}
+
+// DataVersion returns the global data version bumped by every import or
+// removal. With the query cache on it is re-read at most every
+// versionCheckInterval.
+func (db *DB) DataVersion(ctx context.Context) (int64, error) {
+	var version int64
+	if db.Cache != nil {
+		var stale bool
+		if version, stale = db.Cache.Version(); !stale {
+			return version, nil
+		}
+	}
+
+	err := db.Pool.QueryRow(ctx, `SELECT version FROM data_version WHERE id = 1`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read data version: %w", err)
+	}
+	if db.Cache != nil {
+		db.Cache.SetVersion(version)
+	}
+
+	return version, nil
+}
+
+// cached returns the result cached under key for the current data version,
+// running fill and caching its result on a miss. Errors are not cached, and
+// queries run uncached when the data version cannot be read.
+func (db *DB) cached(ctx context.Context, key string, fill func() (interface{}, error)) (interface{}, error) {
+	if db.Cache == nil {
+		return fill()
+	}
+
+	version, err := db.DataVersion(ctx)
+	if err != nil {
+		return fill()
+	}
+
+	// The version is part of the key so a result computed from data that
+	// changed mid-query never outlives the version it was read under
+	key = fmt.Sprintf("%d:%s", version, key)
+	if value, ok := db.Cache.Get(key); ok {
+		return value, nil
+	}
+
+	value, err := fill()
+	if err != nil {
+		return nil, err
+	}
+	db.Cache.Set(key, value)
+
+	return value, nil
+}
+
+// cacheKey normalizes the parameters into a cache key: countries are sorted
+// since their order does not change the result
+func (p NamesListParams) cacheKey() string {
+	p.Countries = sortedStrings(p.Countries)
+	return queryKey("names", p)
+}
+
+// cacheKey normalizes the parameters into a cache key: the name is matched
+// case-insensitively and the order of countries does not matter
+func (p NameTrendParams) cacheKey() string {
+	p.Name = strings.ToLower(p.Name)
+	p.Countries = sortedStrings(p.Countries)
+	return queryKey("trend", p)
+}
+
+func queryKey(kind string, params interface{}) string {
+	b, _ := json.Marshal(params)
+	return kind + ":" + string(b)
+}
+
+func sortedStrings(values []string) []string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// GetYearRange returns the years covered by the imported data
+func (db *DB) GetYearRange(ctx context.Context) (*YearRange, error) {
+	value, err := db.cached(ctx, "years", func() (interface{}, error) {
+		return db.queryYearRange(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*YearRange), nil
+}
+
+// GetNamesList runs the names list pipeline, served from the query cache
+// when the same parameters were seen under the current data version
+func (db *DB) GetNamesList(ctx context.Context, params *NamesListParams) (*NamesListResponse, error) {
+	value, err := db.cached(ctx, params.cacheKey(), func() (interface{}, error) {
+		return db.queryNamesList(ctx, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*NamesListResponse), nil
+}
+
+// GetNameTrend returns the trend of one name, served from the query cache
+// when the same parameters were seen under the current data version
+func (db *DB) GetNameTrend(ctx context.Context, params *NameTrendParams) (*NameTrendResponse, error) {
+	value, err := db.cached(ctx, params.cacheKey(), func() (interface{}, error) {
+		return db.queryNameTrend(ctx, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The key ignores the case of the name; echo it as this request spelled it
+	resp := *value.(*NameTrendResponse)
+	resp.Name = params.Name
+	return &resp, nil
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQueryCacheLRU(t *testing.T) {
+	c := NewQueryCache(2, time.Minute)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatalf("Get(a) missed")
+	}
+	c.Set("c", 3) // evicts b, the least recently used
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("Get(b) hit, want evicted")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("Get(a) = %v, %v, want 1, true", v, ok)
+	}
+	if v, ok := c.Get("c"); !ok || v != 3 {
+		t.Errorf("Get(c) = %v, %v, want 3, true", v, ok)
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 3 || stats.Misses != 1 || stats.Evictions != 1 || stats.Entries != 2 {
+		t.Errorf("Stats() = %+v, want 3 hits, 1 miss, 1 eviction, 2 entries", stats)
+	}
+}
+
+func TestQueryCacheTTL(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewQueryCache(10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	now = now.Add(59 * time.Second)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatalf("Get(a) missed before expiry")
+	}
+
+	now = now.Add(2 * time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("Get(a) hit after expiry")
+	}
+	if entries := c.Stats().Entries; entries != 0 {
+		t.Errorf("Entries = %d, want expired entry removed", entries)
+	}
+}
+
+func TestQueryCacheVersion(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewQueryCache(10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	if _, stale := c.Version(); !stale {
+		t.Fatalf("Version() not stale before the first check")
+	}
+
+	c.SetVersion(1)
+	c.Set("a", 1)
+	if v, stale := c.Version(); v != 1 || stale {
+		t.Errorf("Version() = %d, %v, want 1, false", v, stale)
+	}
+
+	now = now.Add(versionCheckInterval)
+	if _, stale := c.Version(); !stale {
+		t.Errorf("Version() not stale after the check interval")
+	}
+
+	// Same version keeps entries
+	c.SetVersion(1)
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("Get(a) missed after re-check of the same version")
+	}
+
+	// New version drops them
+	c.SetVersion(2)
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("Get(a) hit after version change")
+	}
+	if stats := c.Stats(); stats.Entries != 0 || stats.DataVersion != 2 {
+		t.Errorf("Stats() = %+v, want 0 entries at version 2", stats)
+	}
+}
+
+func TestCacheKeyNormalization(t *testing.T) {
+	drift := 1.5
+	a := NamesListParams{YearFrom: 1900, YearTo: 2000, Countries: []string{"US", "SE"}, DriftMin: &drift}
+	b := NamesListParams{YearFrom: 1900, YearTo: 2000, Countries: []string{"SE", "US"}, DriftMin: &drift}
+	if a.cacheKey() != b.cacheKey() {
+		t.Errorf("names keys differ by country order:\n%s\n%s", a.cacheKey(), b.cacheKey())
+	}
+	if a.Countries[0] != "US" {
+		t.Errorf("cacheKey() reordered the caller's countries")
+	}
+
+	otherDrift := 2.5
+	c := b
+	c.DriftMin = &otherDrift
+	if b.cacheKey() == c.cacheKey() {
+		t.Errorf("names keys equal for different drift_min")
+	}
+
+	x := NameTrendParams{Name: "Alex", YearFrom: 1900, YearTo: 2000, Countries: []string{"US"}}
+	y := NameTrendParams{Name: "alex", YearFrom: 1900, YearTo: 2000, Countries: []string{"US"}}
+	if x.cacheKey() != y.cacheKey() {
+		t.Errorf("trend keys differ by name case")
+	}
+	y.Interval = 10
+	if x.cacheKey() == y.cacheKey() {
+		t.Errorf("trend keys equal for different intervals")
+	}
+}
+
+func TestNameTrendCacheKeepsNameCase(t *testing.T) {
+	db := postgresDB(t)
+	db.Cache = NewQueryCache(10, time.Minute)
+
+	params := &NameTrendParams{Name: "MARY", YearFrom: 2000, YearTo: 2001, Suppression: "none", Interval: 1}
+	for _, name := range []string{"MARY", "Mary"} {
+		params.Name = name
+		resp, err := db.GetNameTrend(context.Background(), params)
+		if err != nil {
+			t.Fatalf("GetNameTrend(%s) unexpected error = %v", name, err)
+		}
+		if resp.Name != name {
+			t.Errorf("GetNameTrend(%s) name = %q, want %q", name, resp.Name, name)
+		}
+	}
+	if stats := db.Cache.Stats(); stats.Hits != 1 {
+		t.Errorf("Stats() = %+v, want the second request served from the cache", stats)
+	}
+}
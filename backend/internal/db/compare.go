@@ -0,0 +1,375 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxCompareNames is the most names one compare request may list
+const MaxCompareNames = 10
+
+type CompareMeta struct {
+	DbStart  int `json:"db_start"`
+	DbEnd    int `json:"db_end"`
+	YearFrom int `json:"year_from"`
+	YearTo   int `json:"year_to"`
+	Interval int `json:"interval"`
+}
+
+// CompareSeries is one name of a comparison. Time series of all names cover
+// the same years so they can be plotted against each other directly.
+type CompareSeries struct {
+	Name       string             `json:"name"`
+	Summary    NameTrendSummary   `json:"summary"`
+	Drift      DriftSummary       `json:"drift"`
+	TimeSeries []TimeSeriesPoint  `json:"time_series"`
+	ByCountry  []CountryBreakdown `json:"by_country"`
+}
+
+// Crossover marks a time-series point where Leader overtakes the other name
+// of a pair in total births
+type Crossover struct {
+	Year    int    `json:"year"`
+	YearEnd int    `json:"year_end,omitempty"`
+	Leader  string `json:"leader"`
+}
+
+// ComparePair holds derived stats for two compared names
+type ComparePair struct {
+	NameA       string      `json:"name_a"`
+	NameB       string      `json:"name_b"`
+	PointsAhead [2]int      `json:"points_ahead"` // points where name_a / name_b had more births
+	Crossovers  []Crossover `json:"crossovers"`
+}
+
+type CompareResponse struct {
+	Meta  CompareMeta     `json:"meta"`
+	Names []CompareSeries `json:"names"`
+	Pairs []ComparePair   `json:"pairs"`
+}
+
+type CompareParams struct {
+	Names     []string
+	YearFrom  int
+	YearTo    int
+	Countries []string
+	Interval  int // years per time-series bucket, 1 = yearly
+}
+
+func ParseCompareParams(query url.Values, dbStart, dbEnd int) (*CompareParams, error) {
+	params := &CompareParams{
+		// Defaults
+		YearFrom:  dbStart,
+		YearTo:    dbEnd,
+		Countries: []string{}, // empty = all countries
+		Interval:  1,
+	}
+
+	// Parse names (comma-separated, duplicates ignored case-insensitively)
+	params.Names = parseNameList(query.Get("names"))
+
+	// Parse year_min
+	if v := query.Get("year_min"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("year_min must be an integer")
+		}
+		params.YearFrom = val
+	}
+
+	// Parse year_max
+	if v := query.Get("year_max"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("year_max must be an integer")
+		}
+		params.YearTo = val
+	}
+
+	// Parse countries (comma-separated)
+	if v := query.Get("countries"); v != "" {
+		params.Countries = strings.Split(v, ",")
+	}
+
+	// Parse interval
+	if v := query.Get("interval"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("interval must be an integer")
+		}
+		params.Interval = val
+	}
+
+	// Validate
+	if err := params.Validate(dbStart, dbEnd); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// parseNameList splits a comma-separated names parameter, dropping blanks
+// and case-insensitive duplicates
+func parseNameList(v string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+func (p *CompareParams) Validate(dbStart, dbEnd int) error {
+	if len(p.Names) < 2 || len(p.Names) > MaxCompareNames {
+		return fmt.Errorf("names must list between 2 and %d distinct names", MaxCompareNames)
+	}
+	// Every series has a point per year, so the range must stay within the data
+	if p.YearFrom < dbStart || p.YearFrom > dbEnd {
+		return fmt.Errorf("year_min must be between %d and %d", dbStart, dbEnd)
+	}
+	if p.YearTo < dbStart || p.YearTo > dbEnd {
+		return fmt.Errorf("year_max must be between %d and %d", dbStart, dbEnd)
+	}
+	if p.YearFrom > p.YearTo {
+		return fmt.Errorf("year_min must be <= year_max")
+	}
+	if p.Interval < 1 || p.Interval > 50 {
+		return fmt.Errorf("interval must be between 1 and 50")
+	}
+
+	return nil
+}
+
+// ComputeCrossovers returns the points of two aligned series where the name
+// with more births changes, and how many points each name led. Points where
+// both names have the same count do not change the leader.
+func ComputeCrossovers(nameA, nameB string, a, b []TimeSeriesPoint) (crossovers []Crossover, pointsAhead [2]int) {
+	crossovers = []Crossover{}
+	leader := 0 // 0 = none yet, 1 = a, 2 = b
+	for i := range a {
+		if i >= len(b) {
+			break
+		}
+
+		current := 0
+		switch {
+		case a[i].TotalCount > b[i].TotalCount:
+			current = 1
+		case b[i].TotalCount > a[i].TotalCount:
+			current = 2
+		default:
+			continue
+		}
+		pointsAhead[current-1]++
+
+		if leader != 0 && current != leader {
+			name := nameA
+			if current == 2 {
+				name = nameB
+			}
+			crossovers = append(crossovers, Crossover{Year: a[i].Year, YearEnd: a[i].YearEnd, Leader: name})
+		}
+		leader = current
+	}
+
+	return crossovers, pointsAhead
+}
+
+func (db *DB) GetNamesCompare(ctx context.Context, params *CompareParams) (*CompareResponse, error) {
+	yearRange, err := db.GetYearRange(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get year range: %w", err)
+	}
+
+	var countries interface{}
+	if len(params.Countries) == 0 {
+		countries = nil
+	} else {
+		countries = params.Countries
+	}
+
+	// Requested names are matched case-insensitively, like /api/names/trend
+	index := make(map[string]int, len(params.Names))
+	for i, name := range params.Names {
+		index[strings.ToLower(name)] = i
+	}
+
+	// Query 1: Time series of all names
+	timeSeriesQuery := `
+		SELECT
+			LOWER(n.name) as name_key,
+			n.year,
+			SUM(n.count) as total_count,
+			SUM(CASE WHEN n.gender = 'F' THEN n.count ELSE 0 END) as female_count,
+			SUM(CASE WHEN n.gender = 'M' THEN n.count ELSE 0 END) as male_count,
+			SUM(CASE WHEN n.gender = 'U' THEN n.count ELSE 0 END) as unknown_count
+		FROM names n
+		JOIN countries c ON n.country_id = c.id
+		WHERE n.name ILIKE ANY($1::text[])
+		  AND n.year >= $2
+		  AND n.year <= $3
+		  AND ($4::text[] IS NULL OR c.code = ANY($4::text[]))
+		GROUP BY LOWER(n.name), n.year
+		ORDER BY name_key, n.year
+	`
+
+	rows, err := db.Pool.Query(ctx, timeSeriesQuery,
+		params.Names, params.YearFrom, params.YearTo, countries)
+	if err != nil {
+		return nil, fmt.Errorf("time series query failed: %w", err)
+	}
+	defer rows.Close()
+
+	yearly := make([][]TimeSeriesPoint, len(params.Names))
+	for rows.Next() {
+		var key string
+		var ts TimeSeriesPoint
+		if err := rows.Scan(&key, &ts.Year, &ts.TotalCount, &ts.FemaleCount, &ts.MaleCount, &ts.UnknownCount); err != nil {
+			return nil, fmt.Errorf("time series scan failed: %w", err)
+		}
+		i, ok := index[key]
+		if !ok {
+			continue
+		}
+		if ts.MaleCount+ts.FemaleCount > 0 {
+			ts.GenderBalance = 100.0 * float64(ts.MaleCount) / float64(ts.MaleCount+ts.FemaleCount)
+		}
+		ts.GenderBalanceLow, ts.GenderBalanceHigh, _ = GenderBalanceInterval(ts.MaleCount, ts.FemaleCount)
+		yearly[i] = append(yearly[i], ts)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("time series rows failed: %w", err)
+	}
+
+	// Query 2: By country for all names
+	byCountryQuery := `
+		SELECT
+			LOWER(n.name) as name_key,
+			c.code as country_code,
+			c.name as country_name,
+			SUM(n.count) as total_count,
+			SUM(CASE WHEN n.gender = 'F' THEN n.count ELSE 0 END) as female_count,
+			SUM(CASE WHEN n.gender = 'M' THEN n.count ELSE 0 END) as male_count,
+			SUM(CASE WHEN n.gender = 'U' THEN n.count ELSE 0 END) as unknown_count
+		FROM names n
+		JOIN countries c ON n.country_id = c.id
+		WHERE n.name ILIKE ANY($1::text[])
+		  AND n.year >= $2
+		  AND n.year <= $3
+		  AND ($4::text[] IS NULL OR c.code = ANY($4::text[]))
+		GROUP BY LOWER(n.name), c.code, c.name
+		ORDER BY name_key, total_count DESC
+	`
+
+	rows, err = db.Pool.Query(ctx, byCountryQuery,
+		params.Names, params.YearFrom, params.YearTo, countries)
+	if err != nil {
+		return nil, fmt.Errorf("by country query failed: %w", err)
+	}
+	defer rows.Close()
+
+	byCountry := make([][]CountryBreakdown, len(params.Names))
+	for rows.Next() {
+		var key string
+		var cb CountryBreakdown
+		if err := rows.Scan(&key, &cb.CountryCode, &cb.CountryName, &cb.TotalCount, &cb.FemaleCount, &cb.MaleCount, &cb.UnknownCount); err != nil {
+			return nil, fmt.Errorf("by country scan failed: %w", err)
+		}
+		i, ok := index[key]
+		if !ok {
+			continue
+		}
+		if cb.MaleCount+cb.FemaleCount > 0 {
+			cb.GenderBalance = 100.0 * float64(cb.MaleCount) / float64(cb.MaleCount+cb.FemaleCount)
+		}
+		byCountry[i] = append(byCountry[i], cb)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("by country rows failed: %w", err)
+	}
+
+	// Query 3: Dataset coverage, shared by all names
+	covered, err := db.getCoveredYears(ctx, &NameTrendParams{YearFrom: params.YearFrom, YearTo: params.YearTo}, countries)
+	if err != nil {
+		return nil, fmt.Errorf("coverage query failed: %w", err)
+	}
+
+	response := &CompareResponse{
+		Meta: CompareMeta{
+			DbStart:  yearRange.MinYear,
+			DbEnd:    yearRange.MaxYear,
+			YearFrom: params.YearFrom,
+			YearTo:   params.YearTo,
+			Interval: params.Interval,
+		},
+		Names: make([]CompareSeries, len(params.Names)),
+		Pairs: []ComparePair{},
+	}
+
+	for i, name := range params.Names {
+		dense := DensifyTimeSeries(yearly[i], params.YearFrom, params.YearTo, covered)
+		series := CompareSeries{
+			Name:       name,
+			Summary:    summarizeSeries(yearly[i], byCountry[i]),
+			Drift:      ComputeDriftSummary(dense),
+			TimeSeries: BucketTimeSeries(dense, params.Interval, "none"),
+			ByCountry:  byCountry[i],
+		}
+		if series.ByCountry == nil {
+			series.ByCountry = []CountryBreakdown{}
+		}
+		response.Names[i] = series
+	}
+
+	for i := 0; i < len(response.Names); i++ {
+		for j := i + 1; j < len(response.Names); j++ {
+			a, b := response.Names[i], response.Names[j]
+			crossovers, ahead := ComputeCrossovers(a.Name, b.Name, a.TimeSeries, b.TimeSeries)
+			response.Pairs = append(response.Pairs, ComparePair{
+				NameA:       a.Name,
+				NameB:       b.Name,
+				PointsAhead: ahead,
+				Crossovers:  crossovers,
+			})
+		}
+	}
+
+	return response, nil
+}
+
+// summarizeSeries builds a trend summary from a name's yearly points and
+// country breakdown
+func summarizeSeries(points []TimeSeriesPoint, byCountry []CountryBreakdown) NameTrendSummary {
+	summary := NameTrendSummary{Countries: []string{}}
+	for _, p := range points {
+		summary.TotalCount += p.TotalCount
+		summary.FemaleCount += p.FemaleCount
+		summary.MaleCount += p.MaleCount
+		summary.UnknownCount += p.UnknownCount
+		if summary.NameStart == 0 || p.Year < summary.NameStart {
+			summary.NameStart = p.Year
+		}
+		if p.Year > summary.NameEnd {
+			summary.NameEnd = p.Year
+		}
+	}
+	summary.HasUnknownData = summary.UnknownCount > 0
+	if summary.MaleCount+summary.FemaleCount > 0 {
+		summary.GenderBalance = 100.0 * float64(summary.MaleCount) / float64(summary.MaleCount+summary.FemaleCount)
+	}
+	for _, cb := range byCountry {
+		summary.Countries = append(summary.Countries, cb.CountryCode)
+	}
+	sort.Strings(summary.Countries)
+
+	return summary
+}
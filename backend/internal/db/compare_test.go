@@ -0,0 +1,125 @@
+package db
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseCompareParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     url.Values
+		wantErr   bool
+		errMsg    string
+		checkFunc func(*testing.T, *CompareParams)
+	}{
+		{
+			name: "names are trimmed and deduplicated",
+			query: url.Values{
+				"names": []string{"Alex, Sam,alex,,Jordan"},
+			},
+			wantErr: false,
+			checkFunc: func(t *testing.T, p *CompareParams) {
+				want := []string{"Alex", "Sam", "Jordan"}
+				if !reflect.DeepEqual(p.Names, want) {
+					t.Errorf("Names = %v, want %v", p.Names, want)
+				}
+				if p.YearFrom != 1880 || p.YearTo != 2024 || p.Interval != 1 {
+					t.Errorf("defaults = %d-%d interval %d, want 1880-2024 interval 1", p.YearFrom, p.YearTo, p.Interval)
+				}
+			},
+		},
+		{
+			name: "single name",
+			query: url.Values{
+				"names": []string{"Alex"},
+			},
+			wantErr: true,
+			errMsg:  "names must list between 2 and 10 distinct names",
+		},
+		{
+			name: "too many names",
+			query: url.Values{
+				"names": []string{"A,B,C,D,E,F,G,H,I,J,K"},
+			},
+			wantErr: true,
+			errMsg:  "names must list between 2 and 10 distinct names",
+		},
+		{
+			name: "invalid year range",
+			query: url.Values{
+				"names":    []string{"Alex,Sam"},
+				"year_min": []string{"2000"},
+				"year_max": []string{"1990"},
+			},
+			wantErr: true,
+			errMsg:  "year_min must be <= year_max",
+		},
+		{
+			name: "year_min before the data",
+			query: url.Values{
+				"names":    []string{"Alex,Sam"},
+				"year_min": []string{"0"},
+			},
+			wantErr: true,
+			errMsg:  "year_min must be between 1880 and 2024",
+		},
+		{
+			name: "year_max past the data",
+			query: url.Values{
+				"names":    []string{"Alex,Sam"},
+				"year_max": []string{"5000000"},
+			},
+			wantErr: true,
+			errMsg:  "year_max must be between 1880 and 2024",
+		},
+		{
+			name: "invalid interval",
+			query: url.Values{
+				"names":    []string{"Alex,Sam"},
+				"interval": []string{"ten"},
+			},
+			wantErr: true,
+			errMsg:  "interval must be an integer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := ParseCompareParams(tt.query, 1880, 2024)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseCompareParams() error = nil, want error containing %q", tt.errMsg)
+					return
+				}
+				if tt.errMsg != "" && !contains(err.Error(), tt.errMsg) {
+					t.Errorf("ParseCompareParams() error = %v, want error containing %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("ParseCompareParams() unexpected error = %v", err)
+				return
+			}
+			if tt.checkFunc != nil {
+				tt.checkFunc(t, params)
+			}
+		})
+	}
+}
+
+func TestComputeCrossovers(t *testing.T) {
+	a := []TimeSeriesPoint{{Year: 2000, TotalCount: 10}, {Year: 2001, TotalCount: 5}, {Year: 2002, TotalCount: 5}, {Year: 2003, TotalCount: 20}}
+	b := []TimeSeriesPoint{{Year: 2000, TotalCount: 5}, {Year: 2001, TotalCount: 5}, {Year: 2002, TotalCount: 8}, {Year: 2003, TotalCount: 10}}
+
+	crossovers, ahead := ComputeCrossovers("Alex", "Sam", a, b)
+
+	want := []Crossover{{Year: 2002, Leader: "Sam"}, {Year: 2003, Leader: "Alex"}}
+	if !reflect.DeepEqual(crossovers, want) {
+		t.Errorf("crossovers = %+v, want %+v", crossovers, want)
+	}
+	if ahead != [2]int{2, 1} {
+		t.Errorf("points ahead = %v, want [2 1]", ahead)
+	}
+}
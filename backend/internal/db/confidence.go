@@ -0,0 +1,27 @@
+package db
+
+import "math"
+
+// wilsonZ is the normal quantile for the 95% Wilson score interval
+const wilsonZ = 1.96
+
+// GenderBalanceInterval returns the 95% Wilson score interval for the gender
+// balance (0-100 axis) given male and female counts. ok is false when there
+// is no binary gender data. Mirrors wilson_lower/wilson_upper in migration 005.
+func GenderBalanceInterval(maleCount, femaleCount int) (low, high float64, ok bool) {
+	n := float64(maleCount + femaleCount)
+	if n <= 0 {
+		return 0, 0, false
+	}
+
+	p := float64(maleCount) / n
+	z2 := wilsonZ * wilsonZ
+	denom := 1 + z2/n
+	center := (p + z2/(2*n)) / denom
+	half := wilsonZ * math.Sqrt(p*(1-p)/n+z2/(4*n*n)) / denom
+
+	low = math.Max(0, center-half)
+	high = math.Min(1, center+half)
+
+	return 100 * low, 100 * high, true
+}
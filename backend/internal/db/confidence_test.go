@@ -0,0 +1,61 @@
+package db
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGenderBalanceInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		male     int
+		female   int
+		wantOK   bool
+		wantLow  float64
+		wantHigh float64
+	}{
+		{
+			name:   "no binary data",
+			wantOK: false,
+		},
+		{
+			name:     "small sample is wide",
+			male:     3,
+			female:   2,
+			wantOK:   true,
+			wantLow:  23.07,
+			wantHigh: 88.24,
+		},
+		{
+			name:     "large sample is narrow",
+			male:     18000,
+			female:   12000,
+			wantOK:   true,
+			wantLow:  59.45,
+			wantHigh: 60.55,
+		},
+		{
+			name:     "all male stays within bounds",
+			male:     10,
+			female:   0,
+			wantOK:   true,
+			wantLow:  72.25,
+			wantHigh: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			low, high, ok := GenderBalanceInterval(tt.male, tt.female)
+			if ok != tt.wantOK {
+				t.Fatalf("GenderBalanceInterval() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(low-tt.wantLow) > 0.01 || math.Abs(high-tt.wantHigh) > 0.01 {
+				t.Errorf("GenderBalanceInterval() = (%.2f, %.2f), want (%.2f, %.2f)", low, high, tt.wantLow, tt.wantHigh)
+			}
+		})
+	}
+}
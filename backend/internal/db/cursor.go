@@ -0,0 +1,40 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// NamesCursor marks the last row of a names list page. The next page starts
+// after it in the sort order, so pages stay consistent however deep the
+// client pages and no offset has to be skipped.
+type NamesCursor struct {
+	SortKey    string   `json:"k"`
+	SortOrder  string   `json:"o"`
+	SortValue  *float64 `json:"v"` // primary sort value, nil when NULL or sorting by name
+	TotalCount int      `json:"t"`
+	Name       string   `json:"n"`
+	Rank       int      `json:"r"`
+}
+
+// Encode returns the cursor as an opaque URL-safe token
+func (c NamesCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeNamesCursor parses a token produced by Encode
+func DecodeNamesCursor(token string) (*NamesCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("cursor is invalid")
+	}
+
+	var c NamesCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Name == "" {
+		return nil, fmt.Errorf("cursor is invalid")
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestNamesCursorRoundTrip(t *testing.T) {
+	value := -0.1 + 0.2 // not exactly representable in decimal
+	tests := []struct {
+		name   string
+		cursor NamesCursor
+	}{
+		{
+			name:   "numeric sort value",
+			cursor: NamesCursor{SortKey: "gender_balance", SortOrder: "desc", SortValue: &value, TotalCount: 1234, Name: "Ænes", Rank: 57},
+		},
+		{
+			name:   "NULL sort value",
+			cursor: NamesCursor{SortKey: "name", SortOrder: "asc", TotalCount: 5, Name: "Zoe", Rank: 9000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeNamesCursor(tt.cursor.Encode())
+			if err != nil {
+				t.Fatalf("DecodeNamesCursor() unexpected error = %v", err)
+			}
+			if got.SortKey != tt.cursor.SortKey || got.SortOrder != tt.cursor.SortOrder ||
+				got.TotalCount != tt.cursor.TotalCount || got.Name != tt.cursor.Name || got.Rank != tt.cursor.Rank {
+				t.Errorf("DecodeNamesCursor() = %+v, want %+v", got, tt.cursor)
+			}
+			if (got.SortValue == nil) != (tt.cursor.SortValue == nil) ||
+				(got.SortValue != nil && *got.SortValue != *tt.cursor.SortValue) {
+				t.Errorf("SortValue = %v, want %v exactly", got.SortValue, tt.cursor.SortValue)
+			}
+		})
+	}
+}
+
+func TestDecodeNamesCursorInvalid(t *testing.T) {
+	for _, token := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		if _, err := DecodeNamesCursor(token); err == nil {
+			t.Errorf("DecodeNamesCursor(%q) expected error", token)
+		}
+	}
+}
+
+func TestNamesCursorIgnoresPage(t *testing.T) {
+	query := url.Values{"cursor": {NamesCursor{SortKey: "popularity", SortOrder: "asc", Name: "Ada"}.Encode()}}
+	params, err := ParseNamesListParams(query, 1880, 2024)
+	if err != nil {
+		t.Fatalf("ParseNamesListParams() unexpected error = %v", err)
+	}
+
+	// A cursor resumes past any page an offset could reach
+	params.Page = 0
+	if err := params.Validate(); err != nil {
+		t.Errorf("Validate() in cursor mode error = %v, want nil", err)
+	}
+	params.Cursor = nil
+	if err := params.Validate(); err == nil {
+		t.Errorf("Validate() in offset mode with page 0 expected error")
+	}
+}
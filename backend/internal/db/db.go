@@ -2,13 +2,19 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrUnsupported is returned by readers that cannot answer a query, such as
+// snapshots, which lack the tables some endpoints read
+var ErrUnsupported = errors.New("not supported by this data store")
+
 type DB struct {
-	Pool *pgxpool.Pool
+	Pool  *pgxpool.Pool
+	Cache *QueryCache // nil disables result caching
 }
 
 func New(databaseURL string) (*DB, error) {
@@ -26,6 +32,11 @@ func New(databaseURL string) (*DB, error) {
 	return &DB{Pool: pool}, nil
 }
 
+// Ping checks the database connection
+func (db *DB) Ping(ctx context.Context) error {
+	return db.Pool.Ping(ctx)
+}
+
 func (db *DB) Close() {
 	db.Pool.Close()
 }
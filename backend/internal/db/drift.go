@@ -0,0 +1,109 @@
+package db
+
+import "math"
+
+// DriftSummary describes how a name's gender balance moved over a year window.
+// The definitions mirror the drift_stats stage of GetNamesList.
+type DriftSummary struct {
+	// Slope of the per-year gender balance, in balance points per decade.
+	// Positive values drift toward male, negative toward female.
+	Drift float64 `json:"drift"`
+
+	// Pooled balance over the first and last ten years the name appears
+	EarlyBalance  float64 `json:"early_balance"`
+	EarlyFrom     int     `json:"early_from"`
+	EarlyTo       int     `json:"early_to"`
+	LateBalance   float64 `json:"late_balance"`
+	LateFrom      int     `json:"late_from"`
+	LateTo        int     `json:"late_to"`
+	BalanceChange float64 `json:"balance_change"`
+
+	// Population standard deviation of the per-year balance
+	Volatility float64 `json:"volatility"`
+}
+
+// ComputeDriftSummary computes drift metrics from a year-ordered time series.
+// Years without binary gender data are ignored.
+func ComputeDriftSummary(points []TimeSeriesPoint) DriftSummary {
+	var years, balances []float64
+	for _, p := range points {
+		if p.MaleCount+p.FemaleCount == 0 {
+			continue
+		}
+		years = append(years, float64(p.Year))
+		balances = append(balances, 100.0*float64(p.MaleCount)/float64(p.MaleCount+p.FemaleCount))
+	}
+
+	var summary DriftSummary
+	if len(years) == 0 {
+		return summary
+	}
+
+	firstYear := int(years[0])
+	lastYear := int(years[len(years)-1])
+
+	summary.EarlyFrom = firstYear
+	summary.EarlyTo = firstYear + 9
+	summary.LateFrom = lastYear - 9
+	summary.LateTo = lastYear
+
+	var earlyMale, earlyBinary, lateMale, lateBinary int
+	for _, p := range points {
+		if p.Year >= summary.EarlyFrom && p.Year <= summary.EarlyTo {
+			earlyMale += p.MaleCount
+			earlyBinary += p.MaleCount + p.FemaleCount
+		}
+		if p.Year >= summary.LateFrom && p.Year <= summary.LateTo {
+			lateMale += p.MaleCount
+			lateBinary += p.MaleCount + p.FemaleCount
+		}
+	}
+	if earlyBinary > 0 {
+		summary.EarlyBalance = 100.0 * float64(earlyMale) / float64(earlyBinary)
+	}
+	if lateBinary > 0 {
+		summary.LateBalance = 100.0 * float64(lateMale) / float64(lateBinary)
+	}
+	summary.BalanceChange = summary.LateBalance - summary.EarlyBalance
+
+	if len(years) < 2 {
+		return summary
+	}
+
+	summary.Drift = 10 * linearSlope(years, balances)
+
+	var mean float64
+	for _, b := range balances {
+		mean += b
+	}
+	mean /= float64(len(balances))
+	var variance float64
+	for _, b := range balances {
+		variance += (b - mean) * (b - mean)
+	}
+	summary.Volatility = math.Sqrt(variance / float64(len(balances)))
+
+	return summary
+}
+
+// linearSlope returns the least-squares slope of ys against xs
+// (the same estimator as PostgreSQL's regr_slope)
+func linearSlope(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var sxx, sxy float64
+	for i := range xs {
+		sxx += (xs[i] - meanX) * (xs[i] - meanX)
+		sxy += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+	if sxx == 0 {
+		return 0
+	}
+	return sxy / sxx
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeDriftSummary(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []TimeSeriesPoint
+		want   DriftSummary
+	}{
+		{
+			name:   "empty series",
+			points: nil,
+			want:   DriftSummary{},
+		},
+		{
+			name: "single year has no slope",
+			points: []TimeSeriesPoint{
+				{Year: 2000, FemaleCount: 10, MaleCount: 30},
+			},
+			want: DriftSummary{
+				EarlyBalance: 75, EarlyFrom: 2000, EarlyTo: 2009,
+				LateBalance: 75, LateFrom: 1991, LateTo: 2000,
+			},
+		},
+		{
+			name: "steady drift toward female",
+			points: []TimeSeriesPoint{
+				{Year: 1990, FemaleCount: 20, MaleCount: 80},
+				{Year: 2000, FemaleCount: 50, MaleCount: 50},
+				{Year: 2010, FemaleCount: 80, MaleCount: 20},
+			},
+			want: DriftSummary{
+				Drift:        -30,
+				EarlyBalance: 80, EarlyFrom: 1990, EarlyTo: 1999,
+				LateBalance: 20, LateFrom: 2001, LateTo: 2010,
+				BalanceChange: -60,
+				Volatility:    math.Sqrt(600),
+			},
+		},
+		{
+			name: "years without binary data are skipped",
+			points: []TimeSeriesPoint{
+				{Year: 2000, FemaleCount: 50, MaleCount: 50},
+				{Year: 2001},
+				{Year: 2002, FemaleCount: 50, MaleCount: 50},
+			},
+			want: DriftSummary{
+				EarlyBalance: 50, EarlyFrom: 2000, EarlyTo: 2009,
+				LateBalance: 50, LateFrom: 1993, LateTo: 2002,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeDriftSummary(tt.points)
+			if !driftAlmostEqual(got, tt.want) {
+				t.Errorf("ComputeDriftSummary() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func driftAlmostEqual(a, b DriftSummary) bool {
+	const eps = 1e-9
+	return math.Abs(a.Drift-b.Drift) < eps &&
+		math.Abs(a.EarlyBalance-b.EarlyBalance) < eps &&
+		math.Abs(a.LateBalance-b.LateBalance) < eps &&
+		math.Abs(a.BalanceChange-b.BalanceChange) < eps &&
+		math.Abs(a.Volatility-b.Volatility) < eps &&
+		a.EarlyFrom == b.EarlyFrom && a.EarlyTo == b.EarlyTo &&
+		a.LateFrom == b.LateFrom && a.LateTo == b.LateTo
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DatasetVersion summarizes the parsed datasets of one country, recorded in
+// exports so results can be traced back to the data they came from
+type DatasetVersion struct {
+	Country      string    `json:"country"`
+	Datasets     int       `json:"datasets"`
+	YearFrom     int       `json:"year_from"`
+	YearTo       int       `json:"year_to"`
+	LastParsedAt time.Time `json:"last_parsed_at"`
+}
+
+// GetDatasetVersions returns one summary per country with parsed datasets
+func (db *DB) GetDatasetVersions(ctx context.Context) ([]DatasetVersion, error) {
+	query := `
+		SELECT
+			c.code,
+			COUNT(*),
+			MIN(d.year_from),
+			MAX(d.year_to),
+			MAX(COALESCE(d.parsed_at, d.uploaded_at))
+		FROM name_datasets d
+		JOIN countries c ON d.country_id = c.id
+		WHERE d.parse_status = 'parsed'
+		GROUP BY c.code
+		ORDER BY c.code
+	`
+
+	rows, err := db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []DatasetVersion{}
+	for rows.Next() {
+		var v DatasetVersion
+		if err := rows.Scan(&v.Country, &v.Datasets, &v.YearFrom, &v.YearTo, &v.LastParsedAt); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows failed: %w", err)
+	}
+
+	return versions, nil
+}
+
+// StreamNamesList runs the names list pipeline without pagination and calls
+// emit for every matching name in sort order as rows arrive. Page, page
+// size and cursor are ignored, and country balances are not attached.
+func (db *DB) StreamNamesList(ctx context.Context, params *NamesListParams, emit func(NameRecord) error) error {
+	yearRange, err := db.GetYearRange(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get year range: %w", err)
+	}
+
+	unpaged := *params
+	unpaged.Cursor = nil
+
+	rows, err := db.namesListRows(ctx, &unpaged, nil, 0, yearRange)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row, err := scanNamesListRow(rows, &unpaged)
+		if err != nil {
+			return err
+		}
+		if err := emit(row.NameRecord); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows failed: %w", err)
+	}
+
+	return nil
+}
+
+// FilterValues returns the effective filters and sort, defaults included, as
+// query parameters that ParseNamesListParams reads back to the same params.
+// Pagination is left out.
+func (p *NamesListParams) FilterValues() url.Values {
+	v := url.Values{}
+	v.Set("year_min", strconv.Itoa(p.YearFrom))
+	v.Set("year_max", strconv.Itoa(p.YearTo))
+	if len(p.Countries) > 0 {
+		v.Set("countries", strings.Join(p.Countries, ","))
+	}
+	v.Set("gender_balance_min", strconv.Itoa(p.GenderBalanceMin))
+	v.Set("gender_balance_max", strconv.Itoa(p.GenderBalanceMax))
+	if p.HasBalanceWindow() {
+		v.Set("balance_year_min", strconv.Itoa(p.BalanceYearFrom))
+		v.Set("balance_year_max", strconv.Itoa(p.BalanceYearTo))
+	}
+	v.Set("balance_estimate", p.BalanceEstimate)
+	v.Set("balance_scope", p.BalanceScope)
+	if p.BalanceCountry != "" {
+		v.Set("balance_country", p.BalanceCountry)
+	}
+	if p.MinCount > 0 {
+		v.Set("min_count", strconv.Itoa(p.MinCount))
+	}
+	if p.TopN > 0 {
+		v.Set("top_n", strconv.Itoa(p.TopN))
+	}
+	if p.CoveragePercent > 0 {
+		v.Set("coverage_percent", strconv.FormatFloat(p.CoveragePercent, 'f', -1, 64))
+	}
+	v.Set("normalize", p.Normalize)
+	if p.NameGlob != "" {
+		v.Set("name_glob", p.NameGlob)
+	}
+	setFloat := func(key string, f *float64) {
+		if f != nil {
+			v.Set(key, strconv.FormatFloat(*f, 'f', -1, 64))
+		}
+	}
+	setFloat("drift_min", p.DriftMin)
+	setFloat("drift_max", p.DriftMax)
+	setFloat("volatility_max", p.VolatilityMax)
+	setInt := func(key string, i *int) {
+		if i != nil {
+			v.Set(key, strconv.Itoa(*i))
+		}
+	}
+	setInt("peak_year_min", p.PeakYearMin)
+	setInt("peak_year_max", p.PeakYearMax)
+	setInt("era_start_min", p.EraStartMin)
+	setInt("era_end_max", p.EraEndMax)
+	v.Set("sort_key", p.SortKey)
+	v.Set("sort_order", p.SortOrder)
+
+	return v
+}
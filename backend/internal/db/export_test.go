@@ -0,0 +1,50 @@
+package db
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestFilterValuesRoundTrip(t *testing.T) {
+	queries := []url.Values{
+		{},
+		{
+			"year_min":         []string{"1950"},
+			"year_max":         []string{"2000"},
+			"countries":        []string{"US,SE"},
+			"balance_year_min": []string{"1990"},
+			"balance_scope":    []string{"country"},
+			"balance_country":  []string{"SE"},
+			"balance_estimate": []string{"interval"},
+			"coverage_percent": []string{"92.5"},
+			"normalize":        []string{"per_births"},
+			"name_glob":        []string{"Al*"},
+			"drift_min":        []string{"-1.25"},
+			"volatility_max":   []string{"3"},
+			"peak_year_min":    []string{"1960"},
+			"era_end_max":      []string{"1999"},
+			"sort_key":         []string{"drift"},
+			"sort_order":       []string{"desc"},
+			"page":             []string{"3"},
+		},
+	}
+
+	for _, query := range queries {
+		params, err := ParseNamesListParams(query, 1880, 2024)
+		if err != nil {
+			t.Fatalf("ParseNamesListParams(%v) unexpected error = %v", query, err)
+		}
+
+		again, err := ParseNamesListParams(params.FilterValues(), 1880, 2024)
+		if err != nil {
+			t.Fatalf("ParseNamesListParams(FilterValues()) unexpected error = %v", err)
+		}
+
+		// Pagination is not part of the filters
+		again.Page = params.Page
+		if !reflect.DeepEqual(params, again) {
+			t.Errorf("round trip changed params:\n got %+v\nwant %+v", again, params)
+		}
+	}
+}
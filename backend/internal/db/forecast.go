@@ -0,0 +1,181 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// PointStatusProjected marks forecast points, which are never observed data
+const PointStatusProjected = "projected"
+
+// Fitting uses at most this many of the latest observed years
+const forecastFitYears = 30
+
+// A damped trend needs a few points to separate level, trend and noise
+const forecastMinYears = 5
+
+// forecastZ is the normal quantile for 95% prediction intervals
+const forecastZ = 1.96
+
+// ForecastPoint is a projected share of births for a future year
+type ForecastPoint struct {
+	Year      int     `json:"year"`
+	Status    string  `json:"status"` // always "projected"
+	Share     float64 `json:"share"`
+	ShareLow  float64 `json:"share_low"`
+	ShareHigh float64 `json:"share_high"`
+}
+
+// ForecastModel describes the fitted damped trend model
+type ForecastModel struct {
+	Method       string  `json:"method"`
+	Alpha        float64 `json:"alpha"`
+	Beta         float64 `json:"beta"`
+	Phi          float64 `json:"phi"`
+	Sigma        float64 `json:"sigma"`
+	FitFrom      int     `json:"fit_from"`
+	FitTo        int     `json:"fit_to"`
+	Observations int     `json:"observations"`
+}
+
+// dampedFit holds the final state and errors of one parameter combination
+type dampedFit struct {
+	alpha, beta, phi float64
+	level, trend     float64
+	sse              float64
+}
+
+// fitDampedTrend runs additive damped trend exponential smoothing over ys
+// in state-space form: level += phi*trend + alpha*e, trend = phi*trend + beta*e
+func fitDampedTrend(ys []float64, alpha, beta, phi float64) dampedFit {
+	fit := dampedFit{alpha: alpha, beta: beta, phi: phi, level: ys[0], trend: ys[1] - ys[0]}
+	for _, y := range ys[1:] {
+		e := y - (fit.level + phi*fit.trend)
+		fit.sse += e * e
+		fit.level += phi*fit.trend + alpha*e
+		fit.trend = phi*fit.trend + beta*e
+	}
+	return fit
+}
+
+// ForecastDampedTrend fits a damped trend model to consecutive yearly shares
+// by grid search on the one-step squared error and projects horizon years
+// after the last one. Shares and interval bounds are clamped to [0, 1].
+// It returns nil when there are fewer than forecastMinYears observations.
+func ForecastDampedTrend(years []int, shares []float64, horizon int) (*ForecastModel, []ForecastPoint) {
+	if len(shares) < forecastMinYears || len(years) != len(shares) || horizon < 1 {
+		return nil, nil
+	}
+
+	var best dampedFit
+	best.sse = math.Inf(1)
+	for _, alpha := range []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9} {
+		for _, beta := range []float64{0.01, 0.05, 0.1, 0.2} {
+			if beta > alpha {
+				continue
+			}
+			for _, phi := range []float64{0.8, 0.85, 0.9, 0.95, 0.98} {
+				if fit := fitDampedTrend(shares, alpha, beta, phi); fit.sse < best.sse {
+					best = fit
+				}
+			}
+		}
+	}
+
+	model := &ForecastModel{
+		Method:       "damped_trend",
+		Alpha:        best.alpha,
+		Beta:         best.beta,
+		Phi:          best.phi,
+		Sigma:        math.Sqrt(best.sse / float64(len(shares)-1)),
+		FitFrom:      years[0],
+		FitTo:        years[len(years)-1],
+		Observations: len(shares),
+	}
+
+	// Forecast variance grows by c_j^2 per step, c_j = alpha + beta*(phi + ... + phi^j)
+	points := make([]ForecastPoint, 0, horizon)
+	var phiSum, varianceFactor float64
+	for h := 1; h <= horizon; h++ {
+		phiSum += math.Pow(best.phi, float64(h))
+		if h > 1 {
+			c := best.alpha + best.beta*(phiSum-math.Pow(best.phi, float64(h)))
+			varianceFactor += c * c
+		}
+		share := best.level + phiSum*best.trend
+		margin := forecastZ * model.Sigma * math.Sqrt(1+varianceFactor)
+		points = append(points, ForecastPoint{
+			Year:      model.FitTo + h,
+			Status:    PointStatusProjected,
+			Share:     clampShare(share),
+			ShareLow:  clampShare(share - margin),
+			ShareHigh: clampShare(share + margin),
+		})
+	}
+
+	return model, points
+}
+
+// clampShare limits a projected share to [0, 1]
+func clampShare(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// ForecastInput returns the trailing run of consecutive covered years of a
+// dense yearly series with its shares of births, at most forecastFitYears long
+func ForecastInput(timeSeries []TimeSeriesPoint, births map[int]int64) (years []int, shares []float64) {
+	for i := len(timeSeries) - 1; i >= 0 && len(years) < forecastFitYears; i-- {
+		p := timeSeries[i]
+		total := births[p.Year]
+		if p.Status == PointStatusUncovered || total <= 0 {
+			if len(years) > 0 {
+				break
+			}
+			continue
+		}
+		years = append(years, p.Year)
+		shares = append(shares, float64(p.TotalCount)/float64(total))
+	}
+
+	// Collected newest first
+	for i, j := 0, len(years)-1; i < j; i, j = i+1, j-1 {
+		years[i], years[j] = years[j], years[i]
+		shares[i], shares[j] = shares[j], shares[i]
+	}
+	return years, shares
+}
+
+// getBirthTotals returns all recorded births per year for the selected countries
+func (db *DB) getBirthTotals(ctx context.Context, params *NameTrendParams, countries interface{}) (map[int]int64, error) {
+	query := `
+		SELECT b.year, SUM(b.total_births)
+		FROM country_year_births b
+		JOIN countries c ON b.country_id = c.id
+		WHERE b.year >= $1
+		  AND b.year <= $2
+		  AND ($3::text[] IS NULL OR c.code = ANY($3::text[]))
+		GROUP BY b.year
+	`
+
+	rows, err := db.Pool.Query(ctx, query, params.YearFrom, params.YearTo, countries)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	births := make(map[int]int64)
+	for rows.Next() {
+		var year int
+		var total int64
+		if err := rows.Scan(&year, &total); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		births[year] = total
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows failed: %w", err)
+	}
+
+	return births, nil
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"math"
+	"testing"
+)
+
+func TestForecastDampedTrend(t *testing.T) {
+	t.Run("too few observations", func(t *testing.T) {
+		model, points := ForecastDampedTrend([]int{2020, 2021}, []float64{0.1, 0.2}, 3)
+		if model != nil || points != nil {
+			t.Errorf("ForecastDampedTrend() = %+v, %+v, want nil", model, points)
+		}
+	})
+
+	t.Run("flat series", func(t *testing.T) {
+		years := []int{2015, 2016, 2017, 2018, 2019, 2020}
+		shares := []float64{0.01, 0.01, 0.01, 0.01, 0.01, 0.01}
+		model, points := ForecastDampedTrend(years, shares, 3)
+		if model == nil || len(points) != 3 {
+			t.Fatalf("ForecastDampedTrend() returned %d points, want 3", len(points))
+		}
+		if model.FitFrom != 2015 || model.FitTo != 2020 || model.Observations != 6 {
+			t.Errorf("model fit = %d-%d (%d obs), want 2015-2020 (6 obs)", model.FitFrom, model.FitTo, model.Observations)
+		}
+		for i, p := range points {
+			if p.Year != 2021+i || p.Status != PointStatusProjected {
+				t.Errorf("point %d = year %d status %s, want year %d projected", i, p.Year, p.Status, 2021+i)
+			}
+			if math.Abs(p.Share-0.01) > 1e-12 || p.ShareLow != p.Share || p.ShareHigh != p.Share {
+				t.Errorf("point %d = %+v, want share 0.01 with no spread", i, p)
+			}
+		}
+	})
+
+	t.Run("rising series with noise", func(t *testing.T) {
+		years := []int{2010, 2011, 2012, 2013, 2014, 2015, 2016, 2017}
+		shares := []float64{0.010, 0.012, 0.013, 0.016, 0.017, 0.020, 0.021, 0.024}
+		_, points := ForecastDampedTrend(years, shares, 5)
+		if len(points) != 5 {
+			t.Fatalf("ForecastDampedTrend() returned %d points, want 5", len(points))
+		}
+		if points[0].Share <= shares[len(shares)-1] {
+			t.Errorf("first projected share = %v, want above last observed %v", points[0].Share, shares[len(shares)-1])
+		}
+		for i := 1; i < len(points); i++ {
+			prevWidth := points[i-1].ShareHigh - points[i-1].ShareLow
+			width := points[i].ShareHigh - points[i].ShareLow
+			if width < prevWidth {
+				t.Errorf("interval narrows at step %d: %v < %v", i+1, width, prevWidth)
+			}
+		}
+	})
+}
+
+func TestForecastInput(t *testing.T) {
+	timeSeries := []TimeSeriesPoint{
+		{Year: 2000, Status: PointStatusData, TotalCount: 10},
+		{Year: 2001, Status: PointStatusUncovered},
+		{Year: 2002, Status: PointStatusData, TotalCount: 20},
+		{Year: 2003, Status: PointStatusZero},
+		{Year: 2004, Status: PointStatusUncovered},
+	}
+	births := map[int]int64{2000: 100, 2002: 100, 2003: 100}
+
+	years, shares := ForecastInput(timeSeries, births)
+	if len(years) != 2 || years[0] != 2002 || years[1] != 2003 {
+		t.Fatalf("years = %v, want [2002 2003]", years)
+	}
+	if shares[0] != 0.2 || shares[1] != 0 {
+		t.Errorf("shares = %v, want [0.2 0]", shares)
+	}
+}
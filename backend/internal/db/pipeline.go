@@ -0,0 +1,830 @@
+package db
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// NameCount is the births of one name in one country and year, by sex. It is
+// the input of the in-process pipelines, which stores without a query engine
+// (the in-memory store) load from their own storage.
+type NameCount struct {
+	Name         string
+	CountryCode  string
+	Year         int
+	FemaleCount  int
+	MaleCount    int
+	UnknownCount int
+}
+
+// TotalCount returns the births of all sexes
+func (c NameCount) TotalCount() int {
+	return c.FemaleCount + c.MaleCount + c.UnknownCount
+}
+
+// CountryYearBirths is the births recorded for one country and year
+type CountryYearBirths struct {
+	CountryCode string
+	Year        int
+	TotalBirths int64
+}
+
+// DataYears returns the years of counts the names list pipeline reads: the
+// popularity window, widened to the balance window when one is set
+func (p *NamesListParams) DataYears() (from, to int) {
+	from, to = p.YearFrom, p.YearTo
+	if p.HasBalanceWindow() {
+		from = min(from, p.BalanceYearFrom)
+		to = max(to, p.BalanceYearTo)
+	}
+	return from, to
+}
+
+// EvaluateNamesList runs the names list pipeline of GetNamesList in Go over
+// counts, which must hold every row of the selected countries in
+// params.DataYears(); rows outside the filters are skipped.
+func EvaluateNamesList(counts []NameCount, births []CountryYearBirths, params *NamesListParams, yearRange *YearRange) *NamesListResponse {
+	rows := evaluateNamesList(counts, births, params)
+
+	// total_count is read from the returned rows, so a page past the end
+	// reports 0 like the SQL pipeline
+	var totalCount int
+	var populationTotal int64
+	if len(rows) > 0 {
+		totalCount = len(rows)
+		populationTotal = rows[0].populationTotal
+	}
+
+	// Stage 6: pagination by cursor or offset
+	start := 0
+	if params.Cursor != nil {
+		start = sort.Search(len(rows), func(i int) bool { return rows[i].afterCursor(params) })
+	} else {
+		start = min((params.Page-1)*params.PageSize, len(rows))
+	}
+	page := rows[start:]
+	if len(page) > params.PageSize {
+		page = page[:params.PageSize]
+	} else if len(page) == 0 {
+		totalCount, populationTotal = 0, 0
+	}
+
+	var nextCursor string
+	names := make([]NameRecord, len(page))
+	for i, row := range page {
+		names[i] = row.NameRecord
+	}
+	if start+len(page) < len(rows) {
+		last := page[len(page)-1]
+		nextCursor = NamesCursor{
+			SortKey:    params.SortKey,
+			SortOrder:  params.SortOrder,
+			SortValue:  last.sortValue,
+			TotalCount: last.TotalCount,
+			Name:       last.Name,
+			Rank:       last.Rank,
+		}.Encode()
+	}
+	attachCountryBalancesFrom(counts, params, names)
+
+	meta := NamesListMeta{
+		Page:              params.Page,
+		PageSize:          params.PageSize,
+		TotalCount:        totalCount,
+		TotalPages:        (totalCount + params.PageSize - 1) / params.PageSize,
+		DbStart:           yearRange.MinYear,
+		DbEnd:             yearRange.MaxYear,
+		NextCursor:        nextCursor,
+		PopularitySummary: params.PopularitySummary(names, populationTotal),
+	}
+	if params.Cursor != nil {
+		meta.Page = 0
+	}
+
+	return &NamesListResponse{Meta: meta, Names: names}
+}
+
+// EvaluateNamesListAll returns every name matching params in sort order,
+// without pagination or country balances, like StreamNamesList
+func EvaluateNamesListAll(counts []NameCount, births []CountryYearBirths, params *NamesListParams) []NameRecord {
+	rows := evaluateNamesList(counts, births, params)
+	names := make([]NameRecord, len(rows))
+	for i, row := range rows {
+		names[i] = row.NameRecord
+	}
+	return names
+}
+
+// pipelineName accumulates one name through the stages of the pipeline
+type pipelineName struct {
+	record    NameRecord
+	countries map[string]bool
+	years     map[int]*pipelineYear
+
+	normalizedSum   float64
+	normalizedCells bool
+
+	// Balance window (or per-country scope) counts
+	windowMale, windowBinary int
+	byCountry                map[string]*pipelineYear
+
+	genderBalance, drift, volatility, peakShare *float64
+	windowInRange, countryInRange               *bool
+	popularityValue                             float64
+}
+
+type pipelineYear struct {
+	total, female, male int
+}
+
+// evaluateNamesList runs stages 1 to 5 and sorts the result; rows carry the
+// total and population of the whole filtered list
+func evaluateNamesList(counts []NameCount, births []CountryYearBirths, params *NamesListParams) []namesListRow {
+	matchName := nameMatcher(globPattern(params.NameGlob))
+	selected := func(code string) bool {
+		return len(params.Countries) == 0 || containsString(params.Countries, code)
+	}
+	inWindow := func(year int) bool { return year >= params.YearFrom && year <= params.YearTo }
+
+	// Births per selected country-year: normalization denominator and the
+	// per-year peak share denominator
+	birthsByCell := make(map[string]map[int]int64)
+	yearBirths := make(map[int]int64)
+	cells := 0
+	for _, b := range births {
+		if !selected(b.CountryCode) || !inWindow(b.Year) {
+			continue
+		}
+		if birthsByCell[b.CountryCode] == nil {
+			birthsByCell[b.CountryCode] = make(map[int]int64)
+		}
+		birthsByCell[b.CountryCode][b.Year] = b.TotalBirths
+		yearBirths[b.Year] += b.TotalBirths
+		cells++
+	}
+
+	// Stage 1: Basic filters, Stage 1b: balance rows, Stage 2: aggregation
+	balanceWindow := params.HasBalanceWindow()
+	scoped := params.BalanceScope != "pooled"
+	byName := make(map[string]*pipelineName)
+	var order []string
+	get := func(name string) *pipelineName {
+		pn, ok := byName[name]
+		if !ok {
+			pn = &pipelineName{
+				record:    NameRecord{Name: name},
+				countries: make(map[string]bool),
+				years:     make(map[int]*pipelineYear),
+				byCountry: make(map[string]*pipelineYear),
+			}
+			byName[name] = pn
+			order = append(order, name)
+		}
+		return pn
+	}
+
+	for _, c := range counts {
+		if !selected(c.CountryCode) || !matchName(c.Name) {
+			continue
+		}
+
+		if inWindow(c.Year) {
+			pn := get(c.Name)
+			r := &pn.record
+			total := c.TotalCount()
+			r.TotalCount += total
+			r.FemaleCount += c.FemaleCount
+			r.MaleCount += c.MaleCount
+			r.UnknownCount += c.UnknownCount
+			r.HasUnknownData = r.UnknownCount > 0
+			if r.NameStart == 0 || c.Year < r.NameStart {
+				r.NameStart = c.Year
+			}
+			r.NameEnd = max(r.NameEnd, c.Year)
+			pn.countries[c.CountryCode] = true
+			if b := birthsByCell[c.CountryCode][c.Year]; b > 0 {
+				pn.normalizedSum += 100000.0 * float64(total) / float64(b)
+				pn.normalizedCells = true
+			}
+
+			y := pn.years[c.Year]
+			if y == nil {
+				y = &pipelineYear{}
+				pn.years[c.Year] = y
+			}
+			y.total += total
+			y.female += c.FemaleCount
+			y.male += c.MaleCount
+		}
+
+		inBalance := (!balanceWindow && scoped && inWindow(c.Year)) ||
+			(balanceWindow && c.Year >= params.BalanceYearFrom && c.Year <= params.BalanceYearTo)
+		if !inBalance {
+			continue
+		}
+		pn := get(c.Name)
+		pn.windowMale += c.MaleCount
+		pn.windowBinary += c.FemaleCount + c.MaleCount
+		if scoped && (params.BalanceScope == "every_country" || c.CountryCode == params.BalanceCountry) {
+			bc := pn.byCountry[c.CountryCode]
+			if bc == nil {
+				bc = &pipelineYear{}
+				pn.byCountry[c.CountryCode] = bc
+			}
+			bc.female += c.FemaleCount
+			bc.male += c.MaleCount
+		}
+	}
+
+	inRange := func(male, binary int) bool {
+		if params.BalanceEstimate == "interval" {
+			low, high, _ := GenderBalanceInterval(male, binary-male)
+			return low >= float64(params.GenderBalanceMin) && high <= float64(params.GenderBalanceMax)
+		}
+		balance := 100.0 * float64(male) / float64(binary)
+		return balance >= float64(params.GenderBalanceMin) && balance <= float64(params.GenderBalanceMax)
+	}
+
+	var kept []*pipelineName
+	for _, name := range order {
+		pn := byName[name]
+		// Names with balance rows only do not reach aggregation
+		if len(pn.years) == 0 {
+			continue
+		}
+		r := &pn.record
+
+		var countries []string
+		for code := range pn.countries {
+			countries = append(countries, code)
+		}
+		sort.Strings(countries)
+		r.Countries = countries
+
+		if r.MaleCount+r.FemaleCount > 0 {
+			balance := 100.0 * float64(r.MaleCount) / float64(r.MaleCount+r.FemaleCount)
+			pn.genderBalance = &balance
+			r.GenderBalance = balance
+		}
+		r.GenderBalanceLow, r.GenderBalanceHigh, _ = GenderBalanceInterval(r.MaleCount, r.FemaleCount)
+		if params.Normalize == "per_births" && pn.normalizedCells && cells > 0 {
+			normalized := pn.normalizedSum / float64(cells)
+			r.NormalizedCount = &normalized
+		}
+
+		// Stage 1b: balance window and per-country checks
+		if pn.windowBinary > 0 {
+			balance := 100.0 * float64(pn.windowMale) / float64(pn.windowBinary)
+			r.WindowGenderBalance = &balance
+			ok := inRange(pn.windowMale, pn.windowBinary)
+			pn.windowInRange = &ok
+		}
+		for _, bc := range pn.byCountry {
+			if bc.female+bc.male == 0 {
+				continue
+			}
+			ok := inRange(bc.male, bc.female+bc.male) && (pn.countryInRange == nil || *pn.countryInRange)
+			pn.countryInRange = &ok
+		}
+
+		// Stage 2b and 2c: drift and peak era
+		years := make([]int, 0, len(pn.years))
+		for year := range pn.years {
+			years = append(years, year)
+		}
+		sort.Ints(years)
+		pn.computeDrift(years)
+		pn.computePeak(years, yearBirths)
+		r.YearsSincePeak = params.YearTo - r.PeakYear
+
+		// Stage 3: Gender balance filter
+		var keep bool
+		switch {
+		case scoped:
+			keep = pn.countryInRange != nil && *pn.countryInRange
+		case balanceWindow:
+			keep = pn.windowInRange != nil && *pn.windowInRange
+		case pn.genderBalance == nil:
+			keep = true
+		default:
+			keep = inRange(r.MaleCount, r.MaleCount+r.FemaleCount)
+		}
+		keep = keep &&
+			floatAtLeast(pn.drift, params.DriftMin) &&
+			floatAtMost(pn.drift, params.DriftMax) &&
+			floatAtMost(pn.volatility, params.VolatilityMax) &&
+			(params.PeakYearMin == nil || r.PeakYear >= *params.PeakYearMin) &&
+			(params.PeakYearMax == nil || r.PeakYear <= *params.PeakYearMax) &&
+			(params.EraStartMin == nil || r.EraStart >= *params.EraStartMin) &&
+			(params.EraEndMax == nil || r.EraEnd <= *params.EraEndMax)
+		if !keep {
+			continue
+		}
+
+		// Stage 4: Popularity value
+		if params.Normalize == "per_births" {
+			if r.NormalizedCount != nil {
+				pn.popularityValue = *r.NormalizedCount
+			}
+		} else {
+			pn.popularityValue = float64(r.TotalCount)
+		}
+		kept = append(kept, pn)
+	}
+
+	// Stage 4: Ranks and cumulative shares
+	sort.Slice(kept, func(i, j int) bool {
+		if kept[i].popularityValue != kept[j].popularityValue {
+			return kept[i].popularityValue > kept[j].popularityValue
+		}
+		return kept[i].record.Name < kept[j].record.Name
+	})
+	var populationTotal int64
+	var popularityTotal float64
+	for _, pn := range kept {
+		populationTotal += int64(pn.record.TotalCount)
+		popularityTotal += pn.popularityValue
+	}
+
+	// Stage 5: Popularity filter
+	var rows []namesListRow
+	var cumulative float64
+	for i, pn := range kept {
+		r := &pn.record
+		r.Rank = i + 1
+		cumulative += pn.popularityValue
+		if popularityTotal != 0 {
+			r.CumulativeShare = cumulative / popularityTotal
+		}
+
+		switch {
+		case params.CoveragePercent > 0:
+			if popularityTotal == 0 || r.CumulativeShare > params.CoveragePercent/100.0 {
+				continue
+			}
+		case params.TopN > 0:
+			if r.Rank > params.TopN {
+				continue
+			}
+		case params.MinCount > 0:
+			if r.TotalCount < params.MinCount {
+				continue
+			}
+		}
+
+		rows = append(rows, namesListRow{
+			NameRecord:      *r,
+			populationTotal: populationTotal,
+			sortValue:       pn.sortValue(params),
+		})
+	}
+
+	// Stage 6: Final sorting
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].sortsBefore(&rows[j], params) })
+
+	return rows
+}
+
+// computeDrift fills the drift_stats stage from the name's sorted years: the
+// slope and spread of the per-year balance and the pooled balance of the
+// first and last ten years
+func (pn *pipelineName) computeDrift(sortedYears []int) {
+	var years, balances []float64
+	var earlyMale, earlyBinary, lateMale, lateBinary int
+	first, last := pn.record.NameStart, pn.record.NameEnd
+	for _, year := range sortedYears {
+		y := pn.years[year]
+		binary := y.male + y.female
+		if binary > 0 {
+			years = append(years, float64(year))
+			balances = append(balances, 100.0*float64(y.male)/float64(binary))
+		}
+		if year <= first+9 {
+			earlyMale += y.male
+			earlyBinary += binary
+		}
+		if year >= last-9 {
+			lateMale += y.male
+			lateBinary += binary
+		}
+	}
+
+	r := &pn.record
+	if earlyBinary > 0 {
+		r.EarlyBalance = 100.0 * float64(earlyMale) / float64(earlyBinary)
+	}
+	if lateBinary > 0 {
+		r.LateBalance = 100.0 * float64(lateMale) / float64(lateBinary)
+	}
+	if len(years) < 2 {
+		return
+	}
+
+	// Years are distinct, so with two points the slope is defined
+	drift := 10 * linearSlope(years, balances)
+	pn.drift = &drift
+	r.Drift = drift
+
+	var mean float64
+	for _, b := range balances {
+		mean += b
+	}
+	mean /= float64(len(balances))
+	var variance float64
+	for _, b := range balances {
+		variance += (b - mean) * (b - mean)
+	}
+	volatility := math.Sqrt(variance / float64(len(balances)))
+	pn.volatility = &volatility
+	r.BalanceVolatility = volatility
+}
+
+// computePeak fills the peak_stats stage from the per-year share of births
+func (pn *pipelineName) computePeak(years []int, yearBirths map[int]int64) {
+	r := &pn.record
+	var bestShare *float64
+	bestTotal := 0
+	cumulative := 0
+	for _, year := range years {
+		y := pn.years[year]
+		var share *float64
+		if b := yearBirths[year]; b > 0 {
+			s := float64(y.total) / float64(b)
+			share = &s
+		}
+
+		// Highest share first (NULLs last), then most births, then earliest
+		better := r.PeakYear == 0 ||
+			(share != nil && (bestShare == nil || *share > *bestShare)) ||
+			(floatEqual(share, bestShare) && y.total > bestTotal)
+		if better {
+			r.PeakYear, bestShare, bestTotal = year, share, y.total
+		}
+		if share != nil && (pn.peakShare == nil || *share > *pn.peakShare) {
+			pn.peakShare = share
+		}
+
+		cumulative += y.total
+		if r.EraStart == 0 && float64(cumulative) >= 0.1*float64(r.TotalCount) {
+			r.EraStart = year
+		}
+		if r.EraEnd == 0 && float64(cumulative) >= 0.9*float64(r.TotalCount) {
+			r.EraEnd = year
+		}
+	}
+	if pn.peakShare != nil {
+		r.PeakShare = *pn.peakShare
+	}
+}
+
+// sortValue mirrors sort_value of namesListQuery: the primary sort key,
+// negated for descending order, nil for sort_key=name or missing values
+func (pn *pipelineName) sortValue(params *NamesListParams) *float64 {
+	r := &pn.record
+	var v *float64
+	value := func(f float64) *float64 { return &f }
+	switch params.SortKey {
+	case "popularity":
+		v = value(float64(r.Rank))
+	case "total_count":
+		v = value(float64(r.TotalCount))
+	case "gender_balance":
+		v = pn.genderBalance
+	case "drift":
+		v = pn.drift
+	case "volatility":
+		v = pn.volatility
+	case "peak_year":
+		v = value(float64(r.PeakYear))
+	case "peak_share":
+		v = pn.peakShare
+	case "era_start":
+		v = value(float64(r.EraStart))
+	}
+	if v != nil && params.SortOrder == "desc" {
+		v = value(-*v)
+	}
+	return v
+}
+
+// sortsBefore is the ORDER BY of namesListQuery
+func (row *namesListRow) sortsBefore(other *namesListRow, params *NamesListParams) bool {
+	a, b := row.sortValue, other.sortValue
+	switch {
+	case a != nil && b == nil:
+		return true
+	case a == nil && b != nil:
+		return false
+	case a != nil && *a != *b:
+		return *a < *b
+	}
+	if params.SortKey == "name" && row.Name != other.Name {
+		if params.SortOrder == "asc" {
+			return row.Name < other.Name
+		}
+		return row.Name > other.Name
+	}
+	if row.TotalCount != other.TotalCount {
+		return row.TotalCount > other.TotalCount
+	}
+	return row.Name < other.Name
+}
+
+// afterCursor reports whether row sorts after the position of params.Cursor
+func (row *namesListRow) afterCursor(params *NamesListParams) bool {
+	after := params.Cursor
+	tieAfter := row.TotalCount < after.TotalCount ||
+		(row.TotalCount == after.TotalCount && row.Name > after.Name)
+	switch {
+	case params.SortKey == "name" && params.SortOrder == "asc":
+		return row.Name > after.Name
+	case params.SortKey == "name":
+		return row.Name < after.Name
+	case after.SortValue == nil:
+		return row.sortValue == nil && tieAfter
+	}
+	return row.sortValue == nil ||
+		*row.sortValue > *after.SortValue ||
+		(*row.sortValue == *after.SortValue && tieAfter)
+}
+
+// attachCountryBalancesFrom fills CountryBalances like attachCountryBalances,
+// computed from counts
+func attachCountryBalancesFrom(counts []NameCount, params *NamesListParams, names []NameRecord) {
+	yearFrom, yearTo := params.YearFrom, params.YearTo
+	if params.HasBalanceWindow() {
+		yearFrom, yearTo = params.BalanceYearFrom, params.BalanceYearTo
+	}
+
+	index := make(map[string]int, len(names))
+	for i, nr := range names {
+		index[nr.Name] = i
+	}
+	sums := make(map[string]map[string]*CountryBalance)
+	for _, c := range counts {
+		if _, ok := index[c.Name]; !ok || c.Year < yearFrom || c.Year > yearTo {
+			continue
+		}
+		if len(params.Countries) > 0 && !containsString(params.Countries, c.CountryCode) {
+			continue
+		}
+		if sums[c.Name] == nil {
+			sums[c.Name] = make(map[string]*CountryBalance)
+		}
+		cb := sums[c.Name][c.CountryCode]
+		if cb == nil {
+			cb = &CountryBalance{CountryCode: c.CountryCode}
+			sums[c.Name][c.CountryCode] = cb
+		}
+		cb.FemaleCount += c.FemaleCount
+		cb.MaleCount += c.MaleCount
+	}
+
+	for i := range names {
+		balances := []CountryBalance{}
+		for _, cb := range sums[names[i].Name] {
+			if cb.MaleCount+cb.FemaleCount > 0 {
+				cb.GenderBalance = 100.0 * float64(cb.MaleCount) / float64(cb.MaleCount+cb.FemaleCount)
+			}
+			balances = append(balances, *cb)
+		}
+		sort.Slice(balances, func(a, b int) bool { return balances[a].CountryCode < balances[b].CountryCode })
+		names[i].CountryBalances = balances
+	}
+}
+
+// globPattern converts name_glob to the ILIKE pattern of namesListQuery
+func globPattern(glob string) string {
+	pattern := strings.ReplaceAll(glob, "*", "%")
+	return strings.ReplaceAll(pattern, "?", "_")
+}
+
+// nameMatcher returns a case-insensitive matcher for an ILIKE pattern, where
+// % matches any run of characters and _ exactly one. An empty pattern
+// matches every name.
+func nameMatcher(pattern string) func(string) bool {
+	if pattern == "" {
+		return func(string) bool { return true }
+	}
+
+	var b strings.Builder
+	b.WriteString(`(?is)^`)
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			b.WriteString(`.*`)
+		case '_':
+			b.WriteString(`.`)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString(`$`)
+	re := regexp.MustCompile(b.String())
+	return re.MatchString
+}
+
+// MatchNames returns a case-insensitive matcher for names equal to any of
+// names, as the trend export selects them
+func MatchNames(names []string) func(string) bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[strings.ToLower(name)] = true
+	}
+	return func(name string) bool {
+		return set[strings.ToLower(name)]
+	}
+}
+
+// floatAtLeast and floatAtMost apply an optional bound; a missing value
+// fails any set bound, as NULL comparisons do in SQL
+func floatAtLeast(v, bound *float64) bool {
+	return bound == nil || (v != nil && *v >= *bound)
+}
+
+func floatAtMost(v, bound *float64) bool {
+	return bound == nil || (v != nil && *v <= *bound)
+}
+
+func floatEqual(a, b *float64) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+// DatasetCoverage is the year span of one parsed dataset and the threshold
+// below which it omits counts
+type DatasetCoverage struct {
+	CountryCode          string
+	YearFrom             int
+	YearTo               int
+	SuppressionThreshold int
+}
+
+// EvaluateNameTrend builds the trend of params.Name like GetNameTrend, from
+// counts of the selected countries in the trend range; rows of other names
+// are skipped. Per-year ranks need every name of a year and are left out.
+func EvaluateNameTrend(counts []NameCount, datasets []DatasetCoverage, births []CountryYearBirths, countryNames map[string]string, params *NameTrendParams, yearRange *YearRange) *NameTrendResponse {
+	matchName := nameMatcher(params.Name)
+	selected := func(code string) bool {
+		return len(params.Countries) == 0 || containsString(params.Countries, code)
+	}
+	inRange := func(year int) bool { return year >= params.YearFrom && year <= params.YearTo }
+
+	// Query 1-3: summary, yearly points and per-country totals
+	summary := NameTrendSummary{Countries: []string{}}
+	yearly := make(map[int]*TimeSeriesPoint)
+	byCountry := make(map[string]*CountryBreakdown)
+	type presence struct{ female, male bool }
+	present := make(map[string]map[int]*presence)
+	for _, c := range counts {
+		if !selected(c.CountryCode) || !inRange(c.Year) || !matchName(c.Name) {
+			continue
+		}
+		total := c.TotalCount()
+
+		summary.TotalCount += total
+		summary.FemaleCount += c.FemaleCount
+		summary.MaleCount += c.MaleCount
+		summary.UnknownCount += c.UnknownCount
+		summary.HasUnknownData = summary.UnknownCount > 0
+		if summary.NameStart == 0 || c.Year < summary.NameStart {
+			summary.NameStart = c.Year
+		}
+		summary.NameEnd = max(summary.NameEnd, c.Year)
+
+		ts := yearly[c.Year]
+		if ts == nil {
+			ts = &TimeSeriesPoint{Year: c.Year}
+			yearly[c.Year] = ts
+		}
+		ts.TotalCount += total
+		ts.FemaleCount += c.FemaleCount
+		ts.MaleCount += c.MaleCount
+		ts.UnknownCount += c.UnknownCount
+
+		cb := byCountry[c.CountryCode]
+		if cb == nil {
+			cb = &CountryBreakdown{CountryCode: c.CountryCode, CountryName: countryNames[c.CountryCode]}
+			byCountry[c.CountryCode] = cb
+		}
+		cb.TotalCount += total
+		cb.FemaleCount += c.FemaleCount
+		cb.MaleCount += c.MaleCount
+		cb.UnknownCount += c.UnknownCount
+
+		if present[c.CountryCode] == nil {
+			present[c.CountryCode] = make(map[int]*presence)
+		}
+		p := present[c.CountryCode][c.Year]
+		if p == nil {
+			p = &presence{}
+			present[c.CountryCode][c.Year] = p
+		}
+		p.female = p.female || c.FemaleCount > 0
+		p.male = p.male || c.MaleCount > 0
+	}
+
+	if summary.TotalCount == 0 {
+		summary = NameTrendSummary{Countries: []string{}}
+	}
+	if summary.MaleCount+summary.FemaleCount > 0 {
+		summary.GenderBalance = 100.0 * float64(summary.MaleCount) / float64(summary.MaleCount+summary.FemaleCount)
+	}
+	for code := range byCountry {
+		summary.Countries = append(summary.Countries, code)
+	}
+	sort.Strings(summary.Countries)
+
+	var timeSeries []TimeSeriesPoint
+	for _, ts := range yearly {
+		if ts.MaleCount+ts.FemaleCount > 0 {
+			ts.GenderBalance = 100.0 * float64(ts.MaleCount) / float64(ts.MaleCount+ts.FemaleCount)
+		}
+		ts.GenderBalanceLow, ts.GenderBalanceHigh, _ = GenderBalanceInterval(ts.MaleCount, ts.FemaleCount)
+		timeSeries = append(timeSeries, *ts)
+	}
+	sort.Slice(timeSeries, func(i, j int) bool { return timeSeries[i].Year < timeSeries[j].Year })
+
+	// Coverage and suppression from the selected countries' datasets
+	covered := make(map[int]bool)
+	thresholds := make(map[string]map[int]int)
+	for _, d := range datasets {
+		if !selected(d.CountryCode) {
+			continue
+		}
+		for year := max(d.YearFrom, params.YearFrom); year <= min(d.YearTo, params.YearTo); year++ {
+			covered[year] = true
+			if d.SuppressionThreshold <= 0 {
+				continue
+			}
+			if thresholds[d.CountryCode] == nil {
+				thresholds[d.CountryCode] = make(map[int]int)
+			}
+			thresholds[d.CountryCode][year] = max(thresholds[d.CountryCode][year], d.SuppressionThreshold)
+		}
+	}
+	suppressed := make(map[int]SuppressedCounts)
+	for code, years := range thresholds {
+		for year, threshold := range years {
+			sc := suppressed[year]
+			p := present[code][year]
+			if p == nil || !p.female {
+				sc.FemaleMax += threshold - 1
+			}
+			if p == nil || !p.male {
+				sc.MaleMax += threshold - 1
+			}
+			if sc.FemaleMax > 0 || sc.MaleMax > 0 {
+				suppressed[year] = sc
+			}
+		}
+	}
+
+	timeSeries = DensifyTimeSeries(timeSeries, params.YearFrom, params.YearTo, covered)
+	ApplySuppression(timeSeries, &summary, suppressed, params.Suppression)
+	drift := ComputeDriftSummary(timeSeries)
+
+	// Project the yearly share of births forward
+	var forecastModel *ForecastModel
+	var forecast []ForecastPoint
+	if params.ForecastYears > 0 {
+		totals := make(map[int]int64)
+		for _, b := range births {
+			if selected(b.CountryCode) && inRange(b.Year) {
+				totals[b.Year] += b.TotalBirths
+			}
+		}
+		years, shares := ForecastInput(timeSeries, totals)
+		forecastModel, forecast = ForecastDampedTrend(years, shares, params.ForecastYears)
+	}
+	timeSeries = BucketTimeSeries(timeSeries, params.Interval, params.Suppression)
+
+	breakdown := []CountryBreakdown{}
+	for _, cb := range byCountry {
+		if cb.MaleCount+cb.FemaleCount > 0 {
+			cb.GenderBalance = 100.0 * float64(cb.MaleCount) / float64(cb.MaleCount+cb.FemaleCount)
+		}
+		breakdown = append(breakdown, *cb)
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		if breakdown[i].TotalCount != breakdown[j].TotalCount {
+			return breakdown[i].TotalCount > breakdown[j].TotalCount
+		}
+		return breakdown[i].CountryCode < breakdown[j].CountryCode
+	})
+
+	return &NameTrendResponse{
+		Name: params.Name,
+		Meta: map[string]int{
+			"db_start": yearRange.MinYear,
+			"db_end":   yearRange.MaxYear,
+		},
+		Summary:       summary,
+		Drift:         drift,
+		TimeSeries:    timeSeries,
+		ByCountry:     breakdown,
+		Forecast:      forecast,
+		ForecastModel: forecastModel,
+	}
+}
@@ -0,0 +1,243 @@
+package db
+
+import (
+	"math"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// pipelineCounts is a small two-country dataset for the pipeline tests
+var pipelineCounts = []NameCount{
+	{Name: "Emma", CountryCode: "US", Year: 2000, FemaleCount: 900},
+	{Name: "Emma", CountryCode: "US", Year: 2001, FemaleCount: 1000, MaleCount: 10},
+	{Name: "Emma", CountryCode: "SE", Year: 2001, FemaleCount: 100},
+	{Name: "Noah", CountryCode: "US", Year: 2000, MaleCount: 800},
+	{Name: "Noah", CountryCode: "US", Year: 2001, MaleCount: 700},
+	{Name: "Alex", CountryCode: "US", Year: 2000, FemaleCount: 200, MaleCount: 300},
+	{Name: "Alex", CountryCode: "SE", Year: 2001, FemaleCount: 60, MaleCount: 40},
+	{Name: "Avery", CountryCode: "US", Year: 2001, FemaleCount: 150, MaleCount: 50},
+	{Name: "Kim", CountryCode: "SE", Year: 2000, UnknownCount: 20},
+}
+
+var pipelineBirths = []CountryYearBirths{
+	{CountryCode: "US", Year: 2000, TotalBirths: 10000},
+	{CountryCode: "US", Year: 2001, TotalBirths: 10000},
+	{CountryCode: "SE", Year: 2000, TotalBirths: 1000},
+	{CountryCode: "SE", Year: 2001, TotalBirths: 1000},
+}
+
+func evaluateQuery(t *testing.T, query url.Values) *NamesListResponse {
+	t.Helper()
+	if query.Get("page_size") == "" {
+		query.Set("page_size", "10")
+	}
+	params, err := ParseNamesListParams(query, 2000, 2001)
+	if err != nil {
+		t.Fatalf("ParseNamesListParams(%v) unexpected error = %v", query, err)
+	}
+	return EvaluateNamesList(pipelineCounts, pipelineBirths, params, &YearRange{MinYear: 2000, MaxYear: 2001})
+}
+
+func responseNames(resp *NamesListResponse) []string {
+	names := []string{}
+	for _, nr := range resp.Names {
+		names = append(names, nr.Name)
+	}
+	return names
+}
+
+func TestEvaluateNamesListFilters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query url.Values
+		want  []string
+	}{
+		{
+			name:  "default ranks by total count",
+			query: url.Values{},
+			want:  []string{"Emma", "Noah", "Alex", "Avery", "Kim"},
+		},
+		{
+			name:  "year window",
+			query: url.Values{"year_min": {"2001"}},
+			want:  []string{"Emma", "Noah", "Avery", "Alex"},
+		},
+		{
+			name:  "country filter",
+			query: url.Values{"countries": {"SE"}},
+			want:  []string{"Alex", "Emma", "Kim"},
+		},
+		{
+			name:  "gender balance range keeps names without binary data",
+			query: url.Values{"gender_balance_min": {"30"}, "gender_balance_max": {"70"}},
+			want:  []string{"Alex", "Kim"},
+		},
+		{
+			name:  "every country must be in range",
+			query: url.Values{"gender_balance_min": {"50"}, "balance_scope": {"every_country"}},
+			want:  []string{"Noah"},
+		},
+		{
+			name:  "every country skips selected countries without the name",
+			query: url.Values{"gender_balance_min": {"50"}, "balance_scope": {"every_country"}, "countries": {"US,SE"}},
+			want:  []string{"Noah"}, // Noah has no SE births
+		},
+		{
+			name:  "balance window",
+			query: url.Values{"gender_balance_min": {"30"}, "gender_balance_max": {"70"}, "balance_year_min": {"2001"}},
+			want:  []string{"Alex"},
+		},
+		{
+			name:  "name glob is case-insensitive",
+			query: url.Values{"name_glob": {"a*"}},
+			want:  []string{"Alex", "Avery"},
+		},
+		{
+			name:  "top_n",
+			query: url.Values{"top_n": {"2"}},
+			want:  []string{"Emma", "Noah"},
+		},
+		{
+			name:  "min_count",
+			query: url.Values{"min_count": {"600"}},
+			want:  []string{"Emma", "Noah", "Alex"},
+		},
+		{
+			name:  "sort by name descending",
+			query: url.Values{"sort_key": {"name"}, "sort_order": {"desc"}},
+			want:  []string{"Noah", "Kim", "Emma", "Avery", "Alex"},
+		},
+		{
+			name:  "sort by gender balance puts names without binary data last",
+			query: url.Values{"sort_key": {"gender_balance"}},
+			want:  []string{"Emma", "Avery", "Alex", "Noah", "Kim"},
+		},
+		{
+			name:  "drift filter",
+			query: url.Values{"drift_max": {"0"}},
+			want:  []string{"Noah", "Alex"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := responseNames(evaluateQuery(t, tt.query))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("names = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateNamesListRecord(t *testing.T) {
+	resp := evaluateQuery(t, url.Values{"top_n": {"3"}})
+
+	if resp.Meta.TotalCount != 3 || resp.Meta.TotalPages != 1 {
+		t.Errorf("meta total = %d/%d pages, want 3/1", resp.Meta.TotalCount, resp.Meta.TotalPages)
+	}
+	if resp.Meta.PopularitySummary == nil || resp.Meta.PopularitySummary.PopulationTotal != 4330 {
+		t.Errorf("popularity summary = %+v, want population total 4330", resp.Meta.PopularitySummary)
+	}
+
+	emma := resp.Names[0]
+	if emma.TotalCount != 2010 || emma.FemaleCount != 2000 || emma.MaleCount != 10 {
+		t.Errorf("Emma counts = %d/%d/%d, want 2010/2000/10", emma.TotalCount, emma.FemaleCount, emma.MaleCount)
+	}
+	if emma.NameStart != 2000 || emma.NameEnd != 2001 || !reflect.DeepEqual(emma.Countries, []string{"SE", "US"}) {
+		t.Errorf("Emma span = %d-%d %v, want 2000-2001 [SE US]", emma.NameStart, emma.NameEnd, emma.Countries)
+	}
+	if emma.Rank != 1 || math.Abs(emma.CumulativeShare-2010.0/4330) > 1e-9 {
+		t.Errorf("Emma rank = %d share %v, want 1 share %v", emma.Rank, emma.CumulativeShare, 2010.0/4330)
+	}
+
+	// 2001 has the larger share of births (1110 vs 900 of 11000)
+	if emma.PeakYear != 2001 || emma.YearsSincePeak != 0 {
+		t.Errorf("Emma peak = %d (%d years since), want 2001 (0)", emma.PeakYear, emma.YearsSincePeak)
+	}
+	if emma.EraStart != 2000 || emma.EraEnd != 2001 {
+		t.Errorf("Emma era = %d-%d, want 2000-2001", emma.EraStart, emma.EraEnd)
+	}
+
+	want := []CountryBalance{
+		{CountryCode: "SE", FemaleCount: 100},
+		{CountryCode: "US", FemaleCount: 1900, MaleCount: 10, GenderBalance: 100.0 * 10 / 1910},
+	}
+	if !reflect.DeepEqual(emma.CountryBalances, want) {
+		t.Errorf("Emma country balances = %+v, want %+v", emma.CountryBalances, want)
+	}
+}
+
+func TestEvaluateNamesListPagination(t *testing.T) {
+	var walked []string
+	query := url.Values{"page_size": {"10"}}
+	params, err := ParseNamesListParams(query, 2000, 2001)
+	if err != nil {
+		t.Fatalf("ParseNamesListParams() unexpected error = %v", err)
+	}
+
+	// Page size 10 is the minimum, so cut the data to force several pages
+	var counts []NameCount
+	for i := 0; i < 25; i++ {
+		counts = append(counts, NameCount{Name: string(rune('A'+i)) + "name", CountryCode: "US", Year: 2000, FemaleCount: 100 - i})
+	}
+
+	cursor := ""
+	for pages := 0; pages < 5; pages++ {
+		if cursor != "" {
+			params.Cursor, err = DecodeNamesCursor(cursor)
+			if err != nil {
+				t.Fatalf("DecodeNamesCursor() unexpected error = %v", err)
+			}
+		}
+		resp := EvaluateNamesList(counts, nil, params, &YearRange{MinYear: 2000, MaxYear: 2001})
+		walked = append(walked, responseNames(resp)...)
+		cursor = resp.Meta.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+	if len(walked) != 25 || walked[0] != "Aname" || walked[24] != "Yname" {
+		t.Errorf("cursor walk returned %d names (%v)", len(walked), walked)
+	}
+
+	params.Cursor = nil
+	params.Page = 3
+	resp := EvaluateNamesList(counts, nil, params, &YearRange{MinYear: 2000, MaxYear: 2001})
+	if got := responseNames(resp); len(got) != 5 || got[0] != "Uname" || resp.Meta.NextCursor != "" {
+		t.Errorf("page 3 = %v (next cursor %q), want 5 names from Uname and no cursor", got, resp.Meta.NextCursor)
+	}
+}
+
+func TestEvaluateNameTrend(t *testing.T) {
+	datasets := []DatasetCoverage{
+		{CountryCode: "US", YearFrom: 2000, YearTo: 2001, SuppressionThreshold: 5},
+		{CountryCode: "SE", YearFrom: 2001, YearTo: 2001},
+	}
+	params := &NameTrendParams{Name: "alex", YearFrom: 1999, YearTo: 2001, Interval: 1, Suppression: "none"}
+	names := map[string]string{"US": "United States", "SE": "Sweden"}
+
+	resp := EvaluateNameTrend(pipelineCounts, datasets, pipelineBirths, names, params, &YearRange{MinYear: 2000, MaxYear: 2001})
+
+	if resp.Summary.TotalCount != 600 || !reflect.DeepEqual(resp.Summary.Countries, []string{"SE", "US"}) {
+		t.Errorf("summary = %+v, want 600 births in [SE US]", resp.Summary)
+	}
+
+	statuses := []string{}
+	for _, p := range resp.TimeSeries {
+		statuses = append(statuses, p.Status)
+	}
+	if !reflect.DeepEqual(statuses, []string{PointStatusUncovered, PointStatusData, PointStatusData}) {
+		t.Errorf("statuses = %v", statuses)
+	}
+
+	// The US dataset covers 2001 but lists no Alex: both sexes may be suppressed
+	p := resp.TimeSeries[2]
+	if !p.Censored || p.CensoredFemaleMax != 4 || p.CensoredMaleMax != 4 {
+		t.Errorf("2001 point = %+v, want censored up to 4 per sex", p)
+	}
+
+	if len(resp.ByCountry) != 2 || resp.ByCountry[0].CountryName != "United States" || resp.ByCountry[0].GenderBalance != 60 {
+		t.Errorf("by country = %+v, want United States first at balance 60", resp.ByCountry)
+	}
+}
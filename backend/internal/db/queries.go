@@ -6,6 +6,8 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type YearRange struct {
@@ -13,7 +15,7 @@ type YearRange struct {
 	MaxYear int `json:"max_year"`
 }
 
-func (db *DB) GetYearRange(ctx context.Context) (*YearRange, error) {
+func (db *DB) queryYearRange(ctx context.Context) (*YearRange, error) {
 	query := `
 		SELECT 
 			MIN(year) as min_year,
@@ -87,12 +89,49 @@ type NameRecord struct {
 	TotalCount      int      `json:"total_count"`
 	FemaleCount     int      `json:"female_count"`
 	MaleCount       int      `json:"male_count"`
+	UnknownCount    int      `json:"unknown_count"`
+	HasUnknownData  bool     `json:"has_unknown_data"`
 	GenderBalance   float64  `json:"gender_balance"`
 	Rank            int      `json:"rank"`
 	CumulativeShare float64  `json:"cumulative_share"`
 	NameStart       int      `json:"name_start"`
 	NameEnd         int      `json:"name_end"`
 	Countries       []string `json:"countries"`
+
+	// 95% Wilson score interval for the gender balance
+	GenderBalanceLow  float64 `json:"gender_balance_low"`
+	GenderBalanceHigh float64 `json:"gender_balance_high"`
+
+	// Gender drift metrics over the selected year window
+	Drift             float64 `json:"drift"`
+	EarlyBalance      float64 `json:"early_balance"`
+	LateBalance       float64 `json:"late_balance"`
+	BalanceVolatility float64 `json:"balance_volatility"`
+
+	// Gender balance over the separate balance window, when one is set
+	WindowGenderBalance *float64 `json:"window_gender_balance,omitempty"`
+
+	// Per-country gender balance over the balance window
+	CountryBalances []CountryBalance `json:"country_balances"`
+
+	// Births per 100k averaged over the selected country-years
+	// (only with normalize=per_births)
+	NormalizedCount *float64 `json:"normalized_count,omitempty"`
+
+	// Peak era: the year with the highest share of births, and the span
+	// from the year cumulative births pass 10% to the year they pass 90%
+	PeakYear       int     `json:"peak_year"`
+	PeakShare      float64 `json:"peak_share"`
+	EraStart       int     `json:"era_start"`
+	EraEnd         int     `json:"era_end"`
+	YearsSincePeak int     `json:"years_since_peak"`
+}
+
+type CountryBalance struct {
+	CountryCode   string  `json:"country_code"`
+	FemaleCount   int     `json:"female_count"`
+	MaleCount     int     `json:"male_count"`
+	GenderBalance float64 `json:"gender_balance"`
 }
 
 type NamesListMeta struct {
@@ -102,6 +141,7 @@ type NamesListMeta struct {
 	TotalPages        int                `json:"total_pages"`
 	DbStart           int                `json:"db_start"`
 	DbEnd             int                `json:"db_end"`
+	NextCursor        string             `json:"next_cursor,omitempty"` // Opaque, empty on the last page
 	PopularitySummary *PopularitySummary `json:"popularity_summary,omitempty"`
 }
 
@@ -131,21 +171,53 @@ type NamesListParams struct {
 	GenderBalanceMin int
 	GenderBalanceMax int
 
+	// Optional gender balance window, evaluated independently of the
+	// popularity window (0 = use the popularity window)
+	BalanceYearFrom int
+	BalanceYearTo   int
+
+	// Whether the gender balance filter tests the point estimate or requires
+	// the whole 95% confidence interval inside the range
+	BalanceEstimate string // point, interval
+
+	// Where the gender balance filter applies: pooled across the selected
+	// countries, in every country the name appears in, or in BalanceCountry.
+	// every_country only checks the selected countries where the name has
+	// female or male births; a country without them does not fail the name.
+	BalanceScope   string // pooled, every_country, country
+	BalanceCountry string
+
 	// Popularity filters (only one should be active)
 	MinCount        int
 	TopN            int
 	CoveragePercent float64
 
+	// Popularity basis: raw summed counts, or per-100k births shares so
+	// every selected country carries equal weight
+	Normalize string // none, per_births
+
 	// Name pattern filter
 	NameGlob string
 
+	// Gender drift filters (nil = not set)
+	DriftMin      *float64 // balance points per decade
+	DriftMax      *float64
+	VolatilityMax *float64
+
+	// Peak era filters (nil = not set)
+	PeakYearMin *int
+	PeakYearMax *int
+	EraStartMin *int
+	EraEndMax   *int
+
 	// Sorting
-	SortKey   string // popularity, total_count, name, gender_balance, countries
+	SortKey   string // popularity, total_count, name, gender_balance, countries, drift, volatility, peak_year, peak_share, era_start
 	SortOrder string // asc, desc
 
-	// Pagination
+	// Pagination: offset pages, or keyset after Cursor (nil = offset mode)
 	Page     int
 	PageSize int
+	Cursor   *NamesCursor
 }
 
 func ParseNamesListParams(query url.Values, dbStart, dbEnd int) (*NamesListParams, error) {
@@ -160,6 +232,9 @@ func ParseNamesListParams(query url.Values, dbStart, dbEnd int) (*NamesListParam
 		TopN:             0,
 		CoveragePercent:  0,
 		NameGlob:         "",
+		BalanceScope:     "pooled",
+		Normalize:        "none",
+		BalanceEstimate:  "point",
 		SortKey:          "popularity",
 		SortOrder:        "asc",
 		Page:             1,
@@ -207,6 +282,45 @@ func ParseNamesListParams(query url.Values, dbStart, dbEnd int) (*NamesListParam
 		params.GenderBalanceMax = val
 	}
 
+	// Parse balance_year_min / balance_year_max (either one enables the
+	// balance window, the other bound defaults to the database range)
+	balanceYearMin := query.Get("balance_year_min")
+	balanceYearMax := query.Get("balance_year_max")
+	if balanceYearMin != "" || balanceYearMax != "" {
+		params.BalanceYearFrom = dbStart
+		params.BalanceYearTo = dbEnd
+	}
+	if balanceYearMin != "" {
+		val, err := strconv.Atoi(balanceYearMin)
+		if err != nil {
+			return nil, fmt.Errorf("balance_year_min must be an integer")
+		}
+		params.BalanceYearFrom = val
+	}
+	if balanceYearMax != "" {
+		val, err := strconv.Atoi(balanceYearMax)
+		if err != nil {
+			return nil, fmt.Errorf("balance_year_max must be an integer")
+		}
+		params.BalanceYearTo = val
+	}
+
+	// Parse balance_estimate
+	if v := query.Get("balance_estimate"); v != "" {
+		params.BalanceEstimate = v
+	}
+
+	// Parse balance_scope (every_country skips countries where the name has
+	// no female or male births)
+	if v := query.Get("balance_scope"); v != "" {
+		params.BalanceScope = v
+	}
+
+	// Parse balance_country
+	if v := query.Get("balance_country"); v != "" {
+		params.BalanceCountry = v
+	}
+
 	// Parse min_count
 	if v := query.Get("min_count"); v != "" {
 		val, err := strconv.Atoi(v)
@@ -234,11 +348,79 @@ func ParseNamesListParams(query url.Values, dbStart, dbEnd int) (*NamesListParam
 		params.CoveragePercent = val
 	}
 
+	// Parse normalize
+	if v := query.Get("normalize"); v != "" {
+		params.Normalize = v
+	}
+
 	// Parse name_glob
 	if v := query.Get("name_glob"); v != "" {
 		params.NameGlob = v
 	}
 
+	// Parse drift_min
+	if v := query.Get("drift_min"); v != "" {
+		val, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("drift_min must be a number")
+		}
+		params.DriftMin = &val
+	}
+
+	// Parse drift_max
+	if v := query.Get("drift_max"); v != "" {
+		val, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("drift_max must be a number")
+		}
+		params.DriftMax = &val
+	}
+
+	// Parse volatility_max
+	if v := query.Get("volatility_max"); v != "" {
+		val, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("volatility_max must be a number")
+		}
+		params.VolatilityMax = &val
+	}
+
+	// Parse peak_year_min
+	if v := query.Get("peak_year_min"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("peak_year_min must be an integer")
+		}
+		params.PeakYearMin = &val
+	}
+
+	// Parse peak_year_max
+	if v := query.Get("peak_year_max"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("peak_year_max must be an integer")
+		}
+		params.PeakYearMax = &val
+	}
+
+	// Parse era_start_min
+	if v := query.Get("era_start_min"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("era_start_min must be an integer")
+		}
+		params.EraStartMin = &val
+	}
+
+	// Parse era_end_max
+	if v := query.Get("era_end_max"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("era_end_max must be an integer")
+		}
+		params.EraEndMax = &val
+	}
+
 	// Parse sort_key
 	if v := query.Get("sort_key"); v != "" {
 		params.SortKey = v
@@ -249,6 +431,18 @@ func ParseNamesListParams(query url.Values, dbStart, dbEnd int) (*NamesListParam
 		params.SortOrder = v
 	}
 
+	// Parse cursor
+	if v := query.Get("cursor"); v != "" {
+		if query.Get("page") != "" {
+			return nil, fmt.Errorf("page cannot be combined with cursor")
+		}
+		cursor, err := DecodeNamesCursor(v)
+		if err != nil {
+			return nil, err
+		}
+		params.Cursor = cursor
+	}
+
 	// Parse page
 	if v := query.Get("page"); v != "" {
 		val, err := strconv.Atoi(v)
@@ -278,7 +472,7 @@ func ParseNamesListParams(query url.Values, dbStart, dbEnd int) (*NamesListParam
 func (p *NamesListParams) Validate() error {
 	// Year range validation
 	if p.YearFrom > p.YearTo {
-		return fmt.Errorf("year_from must be <= year_to")
+		return fmt.Errorf("year_min must be <= year_max")
 	}
 
 	// Gender balance validation
@@ -292,8 +486,64 @@ func (p *NamesListParams) Validate() error {
 		return fmt.Errorf("gender_balance_min must be <= gender_balance_max")
 	}
 
-	// Page validation
-	if p.Page < 1 || p.Page > 100 {
+	// Balance window validation
+	if (p.BalanceYearFrom != 0 || p.BalanceYearTo != 0) && !p.HasBalanceWindow() {
+		return fmt.Errorf("balance_year_min and balance_year_max must be positive")
+	}
+	if p.HasBalanceWindow() && p.BalanceYearFrom > p.BalanceYearTo {
+		return fmt.Errorf("balance_year_min must be <= balance_year_max")
+	}
+
+	// Balance estimate validation
+	if p.BalanceEstimate != "point" && p.BalanceEstimate != "interval" {
+		return fmt.Errorf("balance_estimate must be either 'point' or 'interval'")
+	}
+
+	// Balance scope validation
+	switch p.BalanceScope {
+	case "pooled", "every_country":
+		if p.BalanceCountry != "" {
+			return fmt.Errorf("balance_country requires balance_scope=country")
+		}
+	case "country":
+		if p.BalanceCountry == "" {
+			return fmt.Errorf("balance_country is required when balance_scope=country")
+		}
+		if len(p.Countries) > 0 && !containsString(p.Countries, p.BalanceCountry) {
+			return fmt.Errorf("balance_country must be one of the selected countries")
+		}
+	default:
+		return fmt.Errorf("balance_scope must be one of: pooled, every_country, country")
+	}
+
+	// Normalization validation
+	if p.Normalize != "none" && p.Normalize != "per_births" {
+		return fmt.Errorf("normalize must be either 'none' or 'per_births'")
+	}
+
+	// Drift validation
+	if p.DriftMin != nil && p.DriftMax != nil && *p.DriftMin > *p.DriftMax {
+		return fmt.Errorf("drift_min must be <= drift_max")
+	}
+	if p.VolatilityMax != nil && *p.VolatilityMax < 0 {
+		return fmt.Errorf("volatility_max must be >= 0")
+	}
+
+	// Peak era validation
+	if p.PeakYearMin != nil && p.PeakYearMax != nil && *p.PeakYearMin > *p.PeakYearMax {
+		return fmt.Errorf("peak_year_min must be <= peak_year_max")
+	}
+	if p.EraStartMin != nil && p.EraEndMax != nil && *p.EraStartMin > *p.EraEndMax {
+		return fmt.Errorf("era_start_min must be <= era_end_max")
+	}
+
+	// Cursor validation
+	if p.Cursor != nil && (p.Cursor.SortKey != p.SortKey || p.Cursor.SortOrder != p.SortOrder) {
+		return fmt.Errorf("cursor does not match sort_key and sort_order")
+	}
+
+	// Page validation (offset mode only; cursors have no page ceiling)
+	if p.Cursor == nil && (p.Page < 1 || p.Page > 100) {
 		return fmt.Errorf("page must be between 1 and 100")
 	}
 
@@ -309,9 +559,14 @@ func (p *NamesListParams) Validate() error {
 		"name":           true,
 		"gender_balance": true,
 		"countries":      true,
+		"drift":          true,
+		"volatility":     true,
+		"peak_year":      true,
+		"peak_share":     true,
+		"era_start":      true,
 	}
 	if !validSortKeys[p.SortKey] {
-		return fmt.Errorf("sort_key must be one of: popularity, total_count, name, gender_balance, countries")
+		return fmt.Errorf("sort_key must be one of: popularity, total_count, name, gender_balance, countries, drift, volatility, peak_year, peak_share, era_start")
 	}
 
 	// Sort order validation
@@ -322,6 +577,32 @@ func (p *NamesListParams) Validate() error {
 	return nil
 }
 
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// CanUseNameStats reports whether the request covers what name_stats
+// precomputes: every year and country, raw counts and the pooled balance
+func (p *NamesListParams) CanUseNameStats(dbStart, dbEnd int) bool {
+	return p.YearFrom <= dbStart &&
+		p.YearTo >= dbEnd &&
+		len(p.Countries) == 0 &&
+		p.Normalize == "none" &&
+		!p.HasBalanceWindow() &&
+		p.BalanceScope == "pooled"
+}
+
+// HasBalanceWindow reports whether the gender filter uses its own year window
+func (p *NamesListParams) HasBalanceWindow() bool {
+	return p.BalanceYearFrom > 0 && p.BalanceYearTo > 0
+}
+
 // GetActivePopularityFilter returns which popularity filter is active
 func (p *NamesListParams) GetActivePopularityFilter() string {
 	if p.CoveragePercent > 0 {
@@ -336,64 +617,269 @@ func (p *NamesListParams) GetActivePopularityFilter() string {
 	return "none"
 }
 
-func (db *DB) GetNamesList(ctx context.Context, params *NamesListParams) (*NamesListResponse, error) {
-	// This is a complex 6-stage pipeline query
-	// For simplicity, we'll build it with CTEs (Common Table Expressions)
-
-	query := `
+// namesListQuery is the 6-stage names list pipeline, built with CTEs
+// (Common Table Expressions). $12/$13 page it by offset (LIMIT NULL = all
+// rows) and $28-$31 resume after a cursor.
+const namesListQuery = `
 	WITH 
-	-- Stage 1: Basic Filters
+	-- Stage 1: Basic Filters over the per-(name, country, year) rollups
+	-- (empty when the request is served from name_stats)
 	filtered_names AS (
 		SELECT 
-			n.name,
-			n.year,
-			n.gender,
-			n.count,
-			c.code as country_code
-		FROM names n
-		JOIN countries c ON n.country_id = c.id
-		WHERE n.year >= $1 
-		  AND n.year <= $2
+			r.name,
+			r.year,
+			r.total_count,
+			r.female_count,
+			r.male_count,
+			c.code as country_code,
+			b.total_births
+		FROM name_year_rollups r
+		JOIN countries c ON r.country_id = c.id
+		LEFT JOIN country_year_births b ON b.country_id = r.country_id AND b.year = r.year
+		WHERE NOT $27
+		  AND r.year >= $1 
+		  AND r.year <= $2
 		  AND ($3::text[] IS NULL OR c.code = ANY($3::text[]))
-		  AND ($4 = '' OR n.name ILIKE $4)
+		  AND ($4 = '' OR r.name ILIKE $4)
+	),
+	-- Number of selected country-years with births totals (normalization denominator)
+	birth_cells AS (
+		SELECT COUNT(*) as cells
+		FROM country_year_births b
+		JOIN countries c ON b.country_id = c.id
+		WHERE b.year >= $1
+		  AND b.year <= $2
+		  AND ($3::text[] IS NULL OR c.code = ANY($3::text[]))
+	),
+	-- All births per selected year (peak share denominator)
+	year_births AS (
+		SELECT b.year, SUM(b.total_births) as births
+		FROM country_year_births b
+		JOIN countries c ON b.country_id = c.id
+		WHERE b.year >= $1
+		  AND b.year <= $2
+		  AND ($3::text[] IS NULL OR c.code = ANY($3::text[]))
+		GROUP BY b.year
+	),
+	-- Stage 1b: Rows the gender balance filter is evaluated on: the separate
+	-- balance window when set, otherwise the popularity window rows when a
+	-- per-country scope needs them (empty for the plain pooled filter)
+	balance_rows AS (
+		SELECT name, country_code, female_count, male_count
+		FROM filtered_names
+		WHERE $17 = 0 AND $19 <> 'pooled'
+		UNION ALL
+		SELECT r.name, c.code, r.female_count, r.male_count
+		FROM name_year_rollups r
+		JOIN countries c ON r.country_id = c.id
+		WHERE $17 > 0
+		  AND r.year >= $17
+		  AND r.year <= $18
+		  AND ($3::text[] IS NULL OR c.code = ANY($3::text[]))
+		  AND ($4 = '' OR r.name ILIKE $4)
+	),
+	balance_window_counts AS (
+		SELECT
+			name,
+			SUM(male_count) as male_count,
+			SUM(female_count + male_count) as binary_count
+		FROM balance_rows
+		GROUP BY name
+	),
+	-- window_in_range is NULL when the name has no binary data in the window
+	balance_window AS (
+		SELECT
+			name,
+			100.0 * male_count::float / NULLIF(binary_count, 0) as window_balance,
+			CASE
+				WHEN binary_count = 0 THEN NULL
+				WHEN $22 = 'interval' THEN
+					100 * wilson_lower(male_count, binary_count) >= $5 AND 100 * wilson_upper(male_count, binary_count) <= $6
+				ELSE
+					100.0 * male_count::float / binary_count >= $5 AND 100.0 * male_count::float / binary_count <= $6
+			END as window_in_range
+		FROM balance_window_counts
+	),
+	balance_by_country AS (
+		SELECT
+			name,
+			country_code,
+			SUM(male_count) as male_count,
+			SUM(female_count + male_count) as binary_count
+		FROM balance_rows
+		WHERE $19 <> 'pooled'
+		  AND ($19 = 'every_country' OR country_code = $20)
+		GROUP BY name, country_code
+	),
+	-- NULL when the name has no binary data in any considered country
+	country_check AS (
+		SELECT
+			name,
+			bool_and(CASE
+				WHEN $22 = 'interval' THEN
+					100 * wilson_lower(male_count, binary_count) >= $5 AND 100 * wilson_upper(male_count, binary_count) <= $6
+				ELSE
+					100.0 * male_count::float / binary_count >= $5 AND 100.0 * male_count::float / binary_count <= $6
+			END) as in_range
+		FROM balance_by_country
+		WHERE binary_count > 0
+		GROUP BY name
 	),
 	-- Stage 2: Aggregation
 	aggregated AS (
 		SELECT 
 			name,
-			SUM(count) as total_count,
-			SUM(CASE WHEN gender = 'F' THEN count ELSE 0 END) as female_count,
-			SUM(CASE WHEN gender = 'M' THEN count ELSE 0 END) as male_count,
-			CASE 
-				WHEN SUM(CASE WHEN gender IN ('M','F') THEN count ELSE 0 END) = 0 THEN NULL
-				ELSE 100.0 * SUM(CASE WHEN gender = 'M' THEN count ELSE 0 END)::float / 
-				     NULLIF(SUM(CASE WHEN gender IN ('M','F') THEN count ELSE 0 END), 0)
-			END as gender_balance,
+			SUM(total_count) as total_count,
+			SUM(female_count) as female_count,
+			SUM(male_count) as male_count,
+			100.0 * SUM(male_count)::float / NULLIF(SUM(female_count + male_count), 0) as gender_balance,
 			MIN(year) as name_start,
 			MAX(year) as name_end,
-			ARRAY_AGG(DISTINCT country_code ORDER BY country_code) as countries
+			ARRAY_AGG(DISTINCT country_code ORDER BY country_code) as countries,
+			CASE WHEN $21 = 'per_births' THEN
+				(SUM(100000.0 * total_count / NULLIF(total_births, 0)) / NULLIF((SELECT cells FROM birth_cells), 0))::float
+			END as normalized_count
+		FROM filtered_names
+		GROUP BY name
+	),
+	-- Stage 2b: Per-year gender balance for drift metrics
+	yearly AS (
+		SELECT
+			name,
+			year,
+			SUM(total_count) as total_count,
+			SUM(male_count) as male_count,
+			SUM(female_count + male_count) as binary_count,
+			100.0 * SUM(male_count)::float / NULLIF(SUM(female_count + male_count), 0) as gender_balance,
+			MIN(year) OVER (PARTITION BY name) as first_year,
+			MAX(year) OVER (PARTITION BY name) as last_year
 		FROM filtered_names
+		GROUP BY name, year
+	),
+	drift_stats AS (
+		SELECT
+			name,
+			10 * regr_slope(gender_balance, year) as drift,
+			100.0 * (SUM(male_count) FILTER (WHERE year <= first_year + 9))::float /
+				NULLIF(SUM(binary_count) FILTER (WHERE year <= first_year + 9), 0) as early_balance,
+			100.0 * (SUM(male_count) FILTER (WHERE year >= last_year - 9))::float /
+				NULLIF(SUM(binary_count) FILTER (WHERE year >= last_year - 9), 0) as late_balance,
+			CASE WHEN COUNT(gender_balance) >= 2 THEN stddev_pop(gender_balance) END as balance_volatility
+		FROM yearly
+		GROUP BY name
+	),
+	-- Stage 2c: Peak era from the per-year share of births
+	yearly_share AS (
+		SELECT
+			y.name,
+			y.year,
+			y.total_count,
+			y.total_count::float / NULLIF(yb.births, 0) as share,
+			SUM(y.total_count) OVER (PARTITION BY y.name ORDER BY y.year) as cumulative_count,
+			SUM(y.total_count) OVER (PARTITION BY y.name) as name_total
+		FROM yearly y
+		LEFT JOIN year_births yb ON yb.year = y.year
+	),
+	peak_stats AS (
+		SELECT
+			name,
+			(ARRAY_AGG(year ORDER BY share DESC NULLS LAST, total_count DESC, year ASC))[1] as peak_year,
+			MAX(share) as peak_share,
+			MIN(year) FILTER (WHERE cumulative_count >= 0.1 * name_total) as era_start,
+			MIN(year) FILTER (WHERE cumulative_count >= 0.9 * name_total) as era_end
+		FROM yearly_share
 		GROUP BY name
 	),
+	with_drift AS (
+		SELECT
+			a.*,
+			d.drift,
+			d.early_balance,
+			d.late_balance,
+			d.balance_volatility,
+			bw.window_balance,
+			bw.window_in_range,
+			cc.in_range as country_in_range,
+			ps.peak_year,
+			ps.peak_share,
+			ps.era_start,
+			ps.era_end
+		FROM aggregated a
+		LEFT JOIN drift_stats d ON d.name = a.name
+		LEFT JOIN peak_stats ps ON ps.name = a.name
+		LEFT JOIN balance_window bw ON bw.name = a.name
+		LEFT JOIN country_check cc ON cc.name = a.name
+		UNION ALL
+		-- Whole-range requests read the precomputed aggregates instead
+		SELECT
+			s.name,
+			s.total_count,
+			s.female_count,
+			s.male_count,
+			s.gender_balance,
+			s.earliest_year,
+			s.latest_year,
+			s.country_codes,
+			NULL::float,
+			s.drift,
+			s.early_balance,
+			s.late_balance,
+			s.balance_volatility,
+			NULL::float,
+			NULL::bool,
+			NULL::bool,
+			s.peak_year,
+			s.peak_share,
+			s.era_start,
+			s.era_end
+		FROM name_stats s
+		WHERE $27
+		  AND ($4 = '' OR s.name ILIKE $4)
+	),
 	-- Stage 3: Gender Balance Filter
+	-- With a balance window or a per-country scope the name must have
+	-- binary data inside it
 	gender_filtered AS (
 		SELECT *
-		FROM aggregated
-		WHERE (gender_balance IS NULL OR (gender_balance >= $5 AND gender_balance <= $6))
+		FROM with_drift
+		WHERE CASE
+				WHEN $19 <> 'pooled' THEN country_in_range
+				WHEN $17 > 0 THEN window_in_range
+				WHEN gender_balance IS NULL THEN true
+				WHEN $22 = 'interval' THEN
+					100 * wilson_lower(male_count, male_count + female_count) >= $5
+					AND 100 * wilson_upper(male_count, male_count + female_count) <= $6
+				ELSE gender_balance >= $5 AND gender_balance <= $6
+			END
+		  AND ($14::float8 IS NULL OR drift >= $14)
+		  AND ($15::float8 IS NULL OR drift <= $15)
+		  AND ($16::float8 IS NULL OR balance_volatility <= $16)
+		  AND ($23::int IS NULL OR peak_year >= $23)
+		  AND ($24::int IS NULL OR peak_year <= $24)
+		  AND ($25::int IS NULL OR era_start >= $25)
+		  AND ($26::int IS NULL OR era_end <= $26)
 	),
 	-- Stage 4: Popularity Computation
-	ranked AS (
+	-- Ranks and coverage use the normalized shares when normalize=per_births
+	popularity AS (
 		SELECT
 			*,
-			ROW_NUMBER() OVER (ORDER BY total_count DESC, name ASC) as rank,
-			SUM(total_count) OVER (ORDER BY total_count DESC, name ASC) as cumulative_count,
-			SUM(total_count) OVER () as population_total
+			CASE WHEN $21 = 'per_births' THEN COALESCE(normalized_count, 0) ELSE total_count::float END as popularity_value
 		FROM gender_filtered
 	),
+	ranked AS (
+		SELECT
+			*,
+			ROW_NUMBER() OVER (ORDER BY popularity_value DESC, name ASC) as rank,
+			SUM(popularity_value) OVER (ORDER BY popularity_value DESC, name ASC) as cumulative_value,
+			SUM(total_count) OVER () as population_total,
+			SUM(popularity_value) OVER () as popularity_total
+		FROM popularity
+	),
 	with_cumulative_share AS (
 		SELECT
 			*,
-			cumulative_count::float / NULLIF(population_total, 0) as cumulative_share
+			cumulative_value / NULLIF(popularity_total, 0) as cumulative_share
 		FROM ranked
 	),
 	-- Stage 5: Popularity Filter
@@ -411,34 +897,189 @@ func (db *DB) GetNamesList(ctx context.Context, params *NamesListParams) (*Names
 	-- Count total for pagination
 	total_count AS (
 		SELECT COUNT(*) as cnt FROM popularity_filtered
-	)
+	),
 	-- Stage 6: Final Sorting & Pagination
-	SELECT 
-		pf.*,
-		tc.cnt as total_count_val
-	FROM popularity_filtered pf
-	CROSS JOIN total_count tc
-	ORDER BY
-		CASE
+	-- sort_value is the primary sort key (NULL for sort_key=name); a cursor
+	-- resumes after the (sort_value, total_count, name) it carries
+	sortable AS (
+		SELECT 
+			pf.*,
+			tc.cnt as total_count_val,
+			(CASE
 			WHEN $10 = 'popularity' AND $11 = 'asc' THEN pf.rank
 			WHEN $10 = 'popularity' AND $11 = 'desc' THEN -pf.rank
 			WHEN $10 = 'total_count' AND $11 = 'asc' THEN pf.total_count
 			WHEN $10 = 'total_count' AND $11 = 'desc' THEN -pf.total_count
 			WHEN $10 = 'gender_balance' AND $11 = 'asc' THEN pf.gender_balance
 			WHEN $10 = 'gender_balance' AND $11 = 'desc' THEN -pf.gender_balance
-		END ASC NULLS LAST,
+			WHEN $10 = 'drift' AND $11 = 'asc' THEN pf.drift
+			WHEN $10 = 'drift' AND $11 = 'desc' THEN -pf.drift
+			WHEN $10 = 'volatility' AND $11 = 'asc' THEN pf.balance_volatility
+			WHEN $10 = 'volatility' AND $11 = 'desc' THEN -pf.balance_volatility
+			WHEN $10 = 'peak_year' AND $11 = 'asc' THEN pf.peak_year
+			WHEN $10 = 'peak_year' AND $11 = 'desc' THEN -pf.peak_year
+			WHEN $10 = 'peak_share' AND $11 = 'asc' THEN pf.peak_share
+			WHEN $10 = 'peak_share' AND $11 = 'desc' THEN -pf.peak_share
+			WHEN $10 = 'era_start' AND $11 = 'asc' THEN pf.era_start
+			WHEN $10 = 'era_start' AND $11 = 'desc' THEN -pf.era_start
+			END)::float8 as sort_value
+		FROM popularity_filtered pf
+		CROSS JOIN total_count tc
+	)
+	SELECT *
+	FROM sortable s
+	WHERE NOT $28
+	   OR CASE
+			WHEN $10 = 'name' AND $11 = 'asc' THEN s.name > $31
+			WHEN $10 = 'name' THEN s.name < $31
+			WHEN $29::float8 IS NULL THEN
+				s.sort_value IS NULL
+				AND (s.total_count < $30 OR (s.total_count = $30 AND s.name > $31))
+			ELSE
+				s.sort_value > $29
+				OR s.sort_value IS NULL
+				OR (s.sort_value = $29 AND (s.total_count < $30 OR (s.total_count = $30 AND s.name > $31)))
+		END
+	ORDER BY
+		s.sort_value ASC NULLS LAST,
 		CASE
-			WHEN $10 = 'name' AND $11 = 'asc' THEN pf.name
+			WHEN $10 = 'name' AND $11 = 'asc' THEN s.name
 		END ASC NULLS LAST,
 		CASE
-			WHEN $10 = 'name' AND $11 = 'desc' THEN pf.name
+			WHEN $10 = 'name' AND $11 = 'desc' THEN s.name
 		END DESC NULLS LAST,
 		-- Tie breakers
-		pf.total_count DESC,
-		pf.name ASC
+		s.total_count DESC,
+		s.name ASC
 	LIMIT $12 OFFSET $13
-	`
+`
+
+func (db *DB) queryNamesList(ctx context.Context, params *NamesListParams) (*NamesListResponse, error) {
+	offset := (params.Page - 1) * params.PageSize
+	if params.Cursor != nil {
+		offset = 0 // cursor mode ignores page
+	}
+
+	// Get database year range for meta and the name_stats shortcut
+	yearRange, err := db.GetYearRange(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get year range: %w", err)
+	}
+
+	// One extra row tells whether a next page exists
+	rows, err := db.namesListRows(ctx, params, params.PageSize+1, offset, yearRange)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []NameRecord{}
+	var totalCount int
+	var populationTotal int64
+	var nextCursor string
+	var lastSortValue *float64
+
+	for rows.Next() {
+		row, err := scanNamesListRow(rows, params)
+		if err != nil {
+			return nil, err
+		}
+		totalCount = row.totalCount
+		populationTotal = row.populationTotal
+
+		if len(names) == params.PageSize {
+			// The extra row: the last returned one becomes the next cursor
+			last := names[len(names)-1]
+			nextCursor = NamesCursor{
+				SortKey:    params.SortKey,
+				SortOrder:  params.SortOrder,
+				SortValue:  lastSortValue,
+				TotalCount: last.TotalCount,
+				Name:       last.Name,
+				Rank:       last.Rank,
+			}.Encode()
+			break
+		}
+		lastSortValue = row.sortValue
+		names = append(names, row.NameRecord)
+	}
+
+	// Attach per-country balances for the returned page
+	if err := db.attachCountryBalances(ctx, params, names); err != nil {
+		return nil, fmt.Errorf("failed to get country balances: %w", err)
+	}
+
+	// Calculate total pages
+	totalPages := (totalCount + params.PageSize - 1) / params.PageSize
+
+	meta := NamesListMeta{
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		TotalCount: totalCount,
+		TotalPages: totalPages,
+		DbStart:    yearRange.MinYear,
+		DbEnd:      yearRange.MaxYear,
+		NextCursor: nextCursor,
+
+		PopularitySummary: params.PopularitySummary(names, populationTotal),
+	}
+	if params.Cursor != nil {
+		meta.Page = 0
+	}
+
+	return &NamesListResponse{
+		Meta:  meta,
+		Names: names,
+	}, nil
+}
+
+// PopularitySummary describes the active popularity filter and the values
+// the other filters would need to select the same page of names, or nil
+// without one
+func (p *NamesListParams) PopularitySummary(names []NameRecord, populationTotal int64) *PopularitySummary {
+	activeDriver := p.GetActivePopularityFilter()
+	if activeDriver == "none" || len(names) == 0 {
+		return nil
+	}
+
+	summary := &PopularitySummary{
+		PopulationTotal: populationTotal,
+		ActiveDriver:    activeDriver,
+	}
+
+	// Set active value and derived values based on driver
+	last := names[len(names)-1]
+	switch activeDriver {
+	case "coverage_percent":
+		summary.ActiveValue = p.CoveragePercent
+		summary.DerivedCoveragePercent = p.CoveragePercent
+		summary.DerivedTopN = last.Rank
+		summary.DerivedMinCount = last.TotalCount
+	case "top_n":
+		summary.ActiveValue = float64(p.TopN)
+		summary.DerivedTopN = p.TopN
+		summary.DerivedMinCount = last.TotalCount
+		summary.DerivedCoveragePercent = last.CumulativeShare * 100
+	case "min_count":
+		summary.ActiveValue = float64(p.MinCount)
+		summary.DerivedMinCount = p.MinCount
+		summary.DerivedTopN = last.Rank
+		summary.DerivedCoveragePercent = last.CumulativeShare * 100
+	}
+
+	return summary
+}
 
+// namesListRow is one scanned row of namesListQuery
+type namesListRow struct {
+	NameRecord
+	totalCount      int // rows matching the filters
+	populationTotal int64
+	sortValue       *float64
+}
+
+// namesListRows runs namesListQuery for params; limit nil returns every row
+func (db *DB) namesListRows(ctx context.Context, params *NamesListParams, limit interface{}, offset int, yearRange *YearRange) (pgx.Rows, error) {
 	// Convert name_glob to SQL ILIKE pattern
 	globPattern := ""
 	if params.NameGlob != "" {
@@ -455,9 +1096,15 @@ func (db *DB) GetNamesList(ctx context.Context, params *NamesListParams) (*Names
 		countries = params.Countries
 	}
 
-	offset := (params.Page - 1) * params.PageSize
+	// Keyset position after the cursor's row
+	var after NamesCursor
+	if params.Cursor != nil {
+		after = *params.Cursor
+	}
+
+	useNameStats := params.CanUseNameStats(yearRange.MinYear, yearRange.MaxYear)
 
-	rows, err := db.Pool.Query(ctx, query,
+	rows, err := db.Pool.Query(ctx, namesListQuery,
 		params.YearFrom,         // $1
 		params.YearTo,           // $2
 		countries,               // $3
@@ -469,125 +1116,224 @@ func (db *DB) GetNamesList(ctx context.Context, params *NamesListParams) (*Names
 		params.MinCount,         // $9
 		params.SortKey,          // $10
 		params.SortOrder,        // $11
-		params.PageSize,         // $12
+		limit,                   // $12
 		offset,                  // $13
+		params.DriftMin,         // $14
+		params.DriftMax,         // $15
+		params.VolatilityMax,    // $16
+		params.BalanceYearFrom,  // $17
+		params.BalanceYearTo,    // $18
+		params.BalanceScope,     // $19
+		params.BalanceCountry,   // $20
+		params.Normalize,        // $21
+		params.BalanceEstimate,  // $22
+		params.PeakYearMin,      // $23
+		params.PeakYearMax,      // $24
+		params.EraStartMin,      // $25
+		params.EraEndMax,        // $26
+		useNameStats,            // $27
+		params.Cursor != nil,    // $28
+		after.SortValue,         // $29
+		after.TotalCount,        // $30
+		after.Name,              // $31
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
 	}
-	defer rows.Close()
 
-	var names []NameRecord
-	var totalCount int
-	var populationTotal int64
+	return rows, nil
+}
 
-	for rows.Next() {
-		var nr NameRecord
-		var genderBalance *float64
-		var totalCountVal int
-		var cumulativeCount int64
+// scanNamesListRow scans the current row of namesListRows
+func scanNamesListRow(rows pgx.Rows, params *NamesListParams) (namesListRow, error) {
+	var row namesListRow
+	nr := &row.NameRecord
+	var genderBalance *float64
+	var drift, earlyBalance, lateBalance, volatility *float64
+	var windowInRange, countryInRange *bool
+	var peakShare *float64
+	var popularityValue, cumulativeValue, popularityTotal float64
+
+	err := rows.Scan(
+		&nr.Name,
+		&nr.TotalCount,
+		&nr.FemaleCount,
+		&nr.MaleCount,
+		&genderBalance,
+		&nr.NameStart,
+		&nr.NameEnd,
+		&nr.Countries,
+		&nr.NormalizedCount,
+		&drift,
+		&earlyBalance,
+		&lateBalance,
+		&volatility,
+		&nr.WindowGenderBalance,
+		&windowInRange,
+		&countryInRange,
+		&nr.PeakYear,
+		&peakShare,
+		&nr.EraStart,
+		&nr.EraEnd,
+		&popularityValue,
+		&nr.Rank,
+		&cumulativeValue,
+		&row.populationTotal,
+		&popularityTotal,
+		&nr.CumulativeShare,
+		&row.totalCount,
+		&row.sortValue,
+	)
+	if err != nil {
+		return row, fmt.Errorf("scan failed: %w", err)
+	}
 
-		err := rows.Scan(
-			&nr.Name,
-			&nr.TotalCount,
-			&nr.FemaleCount,
-			&nr.MaleCount,
-			&genderBalance,
-			&nr.NameStart,
-			&nr.NameEnd,
-			&nr.Countries,
-			&nr.Rank,
-			&cumulativeCount,
-			&populationTotal,
-			&nr.CumulativeShare,
-			&totalCountVal,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("scan failed: %w", err)
-		}
+	if genderBalance != nil {
+		nr.GenderBalance = *genderBalance
+	}
+	// total_count sums every sex, so the rest is unknown
+	nr.UnknownCount = nr.TotalCount - nr.FemaleCount - nr.MaleCount
+	nr.HasUnknownData = nr.UnknownCount > 0
+	nr.GenderBalanceLow, nr.GenderBalanceHigh, _ = GenderBalanceInterval(nr.MaleCount, nr.FemaleCount)
+	if drift != nil {
+		nr.Drift = *drift
+	}
+	if earlyBalance != nil {
+		nr.EarlyBalance = *earlyBalance
+	}
+	if lateBalance != nil {
+		nr.LateBalance = *lateBalance
+	}
+	if volatility != nil {
+		nr.BalanceVolatility = *volatility
+	}
+	if peakShare != nil {
+		nr.PeakShare = *peakShare
+	}
+	nr.YearsSincePeak = params.YearTo - nr.PeakYear
 
-		if genderBalance != nil {
-			nr.GenderBalance = *genderBalance
-		}
+	return row, nil
+}
 
-		totalCount = totalCountVal
-		names = append(names, nr)
+// attachCountryBalances fills CountryBalances for the given page of names,
+// computed over the balance window (or the popularity window when unset)
+func (db *DB) attachCountryBalances(ctx context.Context, params *NamesListParams, names []NameRecord) error {
+	if len(names) == 0 {
+		return nil
 	}
 
-	// Get database year range for meta
-	yearRange, err := db.GetYearRange(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get year range: %w", err)
+	query := `
+		SELECT
+			r.name,
+			c.code as country_code,
+			SUM(r.female_count) as female_count,
+			SUM(r.male_count) as male_count,
+			100.0 * SUM(r.male_count)::float / NULLIF(SUM(r.female_count + r.male_count), 0) as gender_balance
+		FROM name_year_rollups r
+		JOIN countries c ON r.country_id = c.id
+		WHERE r.name = ANY($1)
+		  AND r.year >= $2
+		  AND r.year <= $3
+		  AND ($4::text[] IS NULL OR c.code = ANY($4::text[]))
+		GROUP BY r.name, c.code
+		ORDER BY r.name, c.code
+	`
+
+	yearFrom, yearTo := params.YearFrom, params.YearTo
+	if params.HasBalanceWindow() {
+		yearFrom, yearTo = params.BalanceYearFrom, params.BalanceYearTo
 	}
 
-	// Calculate total pages
-	totalPages := (totalCount + params.PageSize - 1) / params.PageSize
+	var countries interface{}
+	if len(params.Countries) == 0 {
+		countries = nil
+	} else {
+		countries = params.Countries
+	}
 
-	meta := NamesListMeta{
-		Page:       params.Page,
-		PageSize:   params.PageSize,
-		TotalCount: totalCount,
-		TotalPages: totalPages,
-		DbStart:    yearRange.MinYear,
-		DbEnd:      yearRange.MaxYear,
+	nameList := make([]string, len(names))
+	for i, nr := range names {
+		nameList[i] = nr.Name
 	}
 
-	// Add popularity summary if filter is active
-	activeDriver := params.GetActivePopularityFilter()
-	if activeDriver != "none" && len(names) > 0 {
-		meta.PopularitySummary = &PopularitySummary{
-			PopulationTotal: populationTotal,
-			ActiveDriver:    activeDriver,
-		}
-
-		// Set active value and derived values based on driver
-		switch activeDriver {
-		case "coverage_percent":
-			meta.PopularitySummary.ActiveValue = params.CoveragePercent
-			meta.PopularitySummary.DerivedCoveragePercent = params.CoveragePercent
-			if len(names) > 0 {
-				meta.PopularitySummary.DerivedTopN = names[len(names)-1].Rank
-				meta.PopularitySummary.DerivedMinCount = names[len(names)-1].TotalCount
-			}
-		case "top_n":
-			meta.PopularitySummary.ActiveValue = float64(params.TopN)
-			meta.PopularitySummary.DerivedTopN = params.TopN
-			if len(names) > 0 {
-				meta.PopularitySummary.DerivedMinCount = names[len(names)-1].TotalCount
-				meta.PopularitySummary.DerivedCoveragePercent = names[len(names)-1].CumulativeShare * 100
-			}
-		case "min_count":
-			meta.PopularitySummary.ActiveValue = float64(params.MinCount)
-			meta.PopularitySummary.DerivedMinCount = params.MinCount
-			if len(names) > 0 {
-				meta.PopularitySummary.DerivedTopN = names[len(names)-1].Rank
-				meta.PopularitySummary.DerivedCoveragePercent = names[len(names)-1].CumulativeShare * 100
-			}
+	rows, err := db.Pool.Query(ctx, query, nameList, yearFrom, yearTo, countries)
+	if err != nil {
+		return fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	byName := make(map[string][]CountryBalance)
+	for rows.Next() {
+		var name string
+		var cb CountryBalance
+		var gb *float64
+		if err := rows.Scan(&name, &cb.CountryCode, &cb.FemaleCount, &cb.MaleCount, &gb); err != nil {
+			return fmt.Errorf("scan failed: %w", err)
+		}
+		if gb != nil {
+			cb.GenderBalance = *gb
 		}
+		byName[name] = append(byName[name], cb)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows failed: %w", err)
 	}
 
-	return &NamesListResponse{
-		Meta:  meta,
-		Names: names,
-	}, nil
+	for i := range names {
+		names[i].CountryBalances = byName[names[i].Name]
+		if names[i].CountryBalances == nil {
+			names[i].CountryBalances = []CountryBalance{}
+		}
+	}
+
+	return nil
 }
 
 type NameTrendSummary struct {
-	TotalCount    int      `json:"total_count"`
-	FemaleCount   int      `json:"female_count"`
-	MaleCount     int      `json:"male_count"`
-	GenderBalance float64  `json:"gender_balance"`
-	NameStart     int      `json:"name_start"`
-	NameEnd       int      `json:"name_end"`
-	Countries     []string `json:"countries"`
+	TotalCount     int      `json:"total_count"`
+	FemaleCount    int      `json:"female_count"`
+	MaleCount      int      `json:"male_count"`
+	UnknownCount   int      `json:"unknown_count"`
+	HasUnknownData bool     `json:"has_unknown_data"`
+	GenderBalance  float64  `json:"gender_balance"`
+	NameStart      int      `json:"name_start"`
+	NameEnd        int      `json:"name_end"`
+	Countries      []string `json:"countries"`
+
+	// Balance range with suppressed births imputed (suppression=bounds only)
+	ImputedBalanceLow  *float64 `json:"imputed_balance_low,omitempty"`
+	ImputedBalanceHigh *float64 `json:"imputed_balance_high,omitempty"`
 }
 
 type TimeSeriesPoint struct {
-	Year          int     `json:"year"`
-	TotalCount    int     `json:"total_count"`
-	FemaleCount   int     `json:"female_count"`
-	MaleCount     int     `json:"male_count"`
-	GenderBalance float64 `json:"gender_balance"`
+	Year              int     `json:"year"`
+	YearEnd           int     `json:"year_end,omitempty"` // last year of the bucket (interval > 1 only)
+	Status            string  `json:"status"`             // data, zero, uncovered
+	TotalCount        int     `json:"total_count"`
+	FemaleCount       int     `json:"female_count"`
+	MaleCount         int     `json:"male_count"`
+	UnknownCount      int     `json:"unknown_count"`
+	GenderBalance     float64 `json:"gender_balance"`
+	GenderBalanceLow  float64 `json:"gender_balance_low"`
+	GenderBalanceHigh float64 `json:"gender_balance_high"`
+
+	// Set when a covering dataset may have suppressed small counts for this
+	// year; the max fields give how many births per sex may be missing
+	Censored          bool `json:"censored"`
+	CensoredFemaleMax int  `json:"censored_female_max,omitempty"`
+	CensoredMaleMax   int  `json:"censored_male_max,omitempty"`
+
+	// Balance range with suppressed births imputed (suppression=bounds only)
+	ImputedBalanceLow  *float64 `json:"imputed_balance_low,omitempty"`
+	ImputedBalanceHigh *float64 `json:"imputed_balance_high,omitempty"`
+
+	// Rank that year (1 = most popular), overall and within each sex, and
+	// the share of all births. Only set on yearly series for all countries
+	// or a single country.
+	Rank          *int     `json:"rank,omitempty"`
+	FemaleRank    *int     `json:"female_rank,omitempty"`
+	MaleRank      *int     `json:"male_rank,omitempty"`
+	ShareOfBirths *float64 `json:"share_of_births,omitempty"`
 }
 
 type CountryBreakdown struct {
@@ -596,6 +1342,7 @@ type CountryBreakdown struct {
 	TotalCount    int     `json:"total_count"`
 	FemaleCount   int     `json:"female_count"`
 	MaleCount     int     `json:"male_count"`
+	UnknownCount  int     `json:"unknown_count"`
 	GenderBalance float64 `json:"gender_balance"`
 }
 
@@ -603,18 +1350,26 @@ type NameTrendResponse struct {
 	Name       string             `json:"name"`
 	Meta       map[string]int     `json:"meta"`
 	Summary    NameTrendSummary   `json:"summary"`
+	Drift      DriftSummary       `json:"drift"`
 	TimeSeries []TimeSeriesPoint  `json:"time_series"`
 	ByCountry  []CountryBreakdown `json:"by_country"`
+
+	// Projected shares of births after the last observed year (forecast_years only)
+	Forecast      []ForecastPoint `json:"forecast,omitempty"`
+	ForecastModel *ForecastModel  `json:"forecast_model,omitempty"`
 }
 
 type NameTrendParams struct {
-	Name      string
-	YearFrom  int
-	YearTo    int
-	Countries []string
+	Name          string
+	YearFrom      int
+	YearTo        int
+	Countries     []string
+	Suppression   string // none, bounds
+	Interval      int    // years per time-series bucket, 1 = yearly
+	ForecastYears int    // years to project past the last observed year, 0 = none
 }
 
-func (db *DB) GetNameTrend(ctx context.Context, params *NameTrendParams) (*NameTrendResponse, error) {
+func (db *DB) queryNameTrend(ctx context.Context, params *NameTrendParams) (*NameTrendResponse, error) {
 	// Get database year range for meta
 	yearRange, errRange := db.GetYearRange(ctx)
 	if errRange != nil {
@@ -627,6 +1382,7 @@ func (db *DB) GetNameTrend(ctx context.Context, params *NameTrendParams) (*NameT
 			SUM(n.count) as total_count,
 			SUM(CASE WHEN n.gender = 'F' THEN n.count ELSE 0 END) as female_count,
 			SUM(CASE WHEN n.gender = 'M' THEN n.count ELSE 0 END) as male_count,
+			SUM(CASE WHEN n.gender = 'U' THEN n.count ELSE 0 END) as unknown_count,
 			CASE 
 				WHEN SUM(CASE WHEN n.gender IN ('M','F') THEN n.count ELSE 0 END) = 0 THEN NULL
 				ELSE 100.0 * SUM(CASE WHEN n.gender = 'M' THEN n.count ELSE 0 END)::float / 
@@ -655,6 +1411,7 @@ func (db *DB) GetNameTrend(ctx context.Context, params *NameTrendParams) (*NameT
 	var totalCount *int
 	var femaleCount *int
 	var maleCount *int
+	var unknownCount *int
 	var nameStart *int
 	var nameEnd *int
 
@@ -663,6 +1420,7 @@ func (db *DB) GetNameTrend(ctx context.Context, params *NameTrendParams) (*NameT
 		&totalCount,
 		&femaleCount,
 		&maleCount,
+		&unknownCount,
 		&genderBalance,
 		&nameStart,
 		&nameEnd,
@@ -685,6 +1443,8 @@ func (db *DB) GetNameTrend(ctx context.Context, params *NameTrendParams) (*NameT
 		summary.TotalCount = *totalCount
 		summary.FemaleCount = *femaleCount
 		summary.MaleCount = *maleCount
+		summary.UnknownCount = *unknownCount
+		summary.HasUnknownData = *unknownCount > 0
 		summary.NameStart = *nameStart
 		summary.NameEnd = *nameEnd
 	}
@@ -700,6 +1460,7 @@ func (db *DB) GetNameTrend(ctx context.Context, params *NameTrendParams) (*NameT
 			SUM(n.count) as total_count,
 			SUM(CASE WHEN n.gender = 'F' THEN n.count ELSE 0 END) as female_count,
 			SUM(CASE WHEN n.gender = 'M' THEN n.count ELSE 0 END) as male_count,
+			SUM(CASE WHEN n.gender = 'U' THEN n.count ELSE 0 END) as unknown_count,
 			CASE 
 				WHEN SUM(CASE WHEN n.gender IN ('M','F') THEN n.count ELSE 0 END) = 0 THEN NULL
 				ELSE 100.0 * SUM(CASE WHEN n.gender = 'M' THEN n.count ELSE 0 END)::float / 
@@ -726,16 +1487,55 @@ func (db *DB) GetNameTrend(ctx context.Context, params *NameTrendParams) (*NameT
 	for rows.Next() {
 		var ts TimeSeriesPoint
 		var gb *float64
-		err := rows.Scan(&ts.Year, &ts.TotalCount, &ts.FemaleCount, &ts.MaleCount, &gb)
+		err := rows.Scan(&ts.Year, &ts.TotalCount, &ts.FemaleCount, &ts.MaleCount, &ts.UnknownCount, &gb)
 		if err != nil {
 			return nil, fmt.Errorf("time series scan failed: %w", err)
 		}
 		if gb != nil {
 			ts.GenderBalance = *gb
 		}
+		ts.GenderBalanceLow, ts.GenderBalanceHigh, _ = GenderBalanceInterval(ts.MaleCount, ts.FemaleCount)
 		timeSeries = append(timeSeries, ts)
 	}
 
+	// Fill every requested year, telling apart years with no births from
+	// years no dataset covers
+	covered, err := db.getCoveredYears(ctx, params, countries)
+	if err != nil {
+		return nil, fmt.Errorf("coverage query failed: %w", err)
+	}
+	timeSeries = DensifyTimeSeries(timeSeries, params.YearFrom, params.YearTo, covered)
+
+	// Flag years where suppressed counts may be hiding births
+	suppressed, err := db.getSuppressedCounts(ctx, params, countries)
+	if err != nil {
+		return nil, fmt.Errorf("suppression query failed: %w", err)
+	}
+	ApplySuppression(timeSeries, &summary, suppressed, params.Suppression)
+	drift := ComputeDriftSummary(timeSeries)
+
+	// Project the yearly share of births forward
+	var forecastModel *ForecastModel
+	var forecast []ForecastPoint
+	if params.ForecastYears > 0 {
+		births, err := db.getBirthTotals(ctx, params, countries)
+		if err != nil {
+			return nil, fmt.Errorf("births query failed: %w", err)
+		}
+		years, shares := ForecastInput(timeSeries, births)
+		forecastModel, forecast = ForecastDampedTrend(years, shares, params.ForecastYears)
+	}
+
+	// Attach precomputed per-year ranks (yearly series only)
+	if params.Interval <= 1 {
+		ranks, err := db.getYearRanks(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("rank query failed: %w", err)
+		}
+		applyYearRanks(timeSeries, ranks)
+	}
+	timeSeries = BucketTimeSeries(timeSeries, params.Interval, params.Suppression)
+
 	// Query 3: By country
 	byCountryQuery := `
 		SELECT 
@@ -744,6 +1544,7 @@ func (db *DB) GetNameTrend(ctx context.Context, params *NameTrendParams) (*NameT
 			SUM(n.count) as total_count,
 			SUM(CASE WHEN n.gender = 'F' THEN n.count ELSE 0 END) as female_count,
 			SUM(CASE WHEN n.gender = 'M' THEN n.count ELSE 0 END) as male_count,
+			SUM(CASE WHEN n.gender = 'U' THEN n.count ELSE 0 END) as unknown_count,
 			CASE 
 				WHEN SUM(CASE WHEN n.gender IN ('M','F') THEN n.count ELSE 0 END) = 0 THEN NULL
 				ELSE 100.0 * SUM(CASE WHEN n.gender = 'M' THEN n.count ELSE 0 END)::float / 
@@ -766,12 +1567,12 @@ func (db *DB) GetNameTrend(ctx context.Context, params *NameTrendParams) (*NameT
 	}
 	defer rows.Close()
 
-	var byCountry []CountryBreakdown
+	byCountry := []CountryBreakdown{}
 	for rows.Next() {
 		var cb CountryBreakdown
 		var gb *float64
 		err := rows.Scan(&cb.CountryCode, &cb.CountryName, &cb.TotalCount,
-			&cb.FemaleCount, &cb.MaleCount, &gb)
+			&cb.FemaleCount, &cb.MaleCount, &cb.UnknownCount, &gb)
 		if err != nil {
 			return nil, fmt.Errorf("by country scan failed: %w", err)
 		}
@@ -787,8 +1588,11 @@ func (db *DB) GetNameTrend(ctx context.Context, params *NameTrendParams) (*NameT
 			"db_start": yearRange.MinYear,
 			"db_end":   yearRange.MaxYear,
 		},
-		Summary:    summary,
-		TimeSeries: timeSeries,
-		ByCountry:  byCountry,
+		Summary:       summary,
+		Drift:         drift,
+		TimeSeries:    timeSeries,
+		ByCountry:     byCountry,
+		Forecast:      forecast,
+		ForecastModel: forecastModel,
 	}, nil
 }
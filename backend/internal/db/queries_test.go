@@ -1,7 +1,9 @@
 package db
 
 import (
+	"context"
 	"net/url"
+	"os"
 	"testing"
 )
 
@@ -39,8 +41,8 @@ func TestParseNamesListParams(t *testing.T) {
 		{
 			name: "custom year range",
 			query: url.Values{
-				"year_from": []string{"2022"},
-				"year_to":   []string{"2023"},
+				"year_min": []string{"2022"},
+				"year_max": []string{"2023"},
 			},
 			dbStart: 2020,
 			dbEnd:   2024,
@@ -55,25 +57,25 @@ func TestParseNamesListParams(t *testing.T) {
 			},
 		},
 		{
-			name: "invalid year_from not integer",
+			name: "invalid year_min not integer",
 			query: url.Values{
-				"year_from": []string{"invalid"},
+				"year_min": []string{"invalid"},
 			},
 			dbStart: 2020,
 			dbEnd:   2024,
 			wantErr: true,
-			errMsg:  "year_from must be an integer",
+			errMsg:  "year_min must be an integer",
 		},
 		{
-			name: "year_from > year_to",
+			name: "year_min > year_max",
 			query: url.Values{
-				"year_from": []string{"2024"},
-				"year_to":   []string{"2022"},
+				"year_min": []string{"2024"},
+				"year_max": []string{"2022"},
 			},
 			dbStart: 2020,
 			dbEnd:   2024,
 			wantErr: true,
-			errMsg:  "year_from must be <= year_to",
+			errMsg:  "year_min must be <= year_max",
 		},
 		{
 			name: "gender balance filters",
@@ -208,6 +210,273 @@ func TestParseNamesListParams(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "drift filters and sort",
+			query: url.Values{
+				"drift_min":      []string{"-5"},
+				"drift_max":      []string{"2.5"},
+				"volatility_max": []string{"10"},
+				"sort_key":       []string{"drift"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: false,
+			checkFunc: func(t *testing.T, p *NamesListParams) {
+				if p.DriftMin == nil || *p.DriftMin != -5 {
+					t.Errorf("DriftMin = %v, want -5", p.DriftMin)
+				}
+				if p.DriftMax == nil || *p.DriftMax != 2.5 {
+					t.Errorf("DriftMax = %v, want 2.5", p.DriftMax)
+				}
+				if p.VolatilityMax == nil || *p.VolatilityMax != 10 {
+					t.Errorf("VolatilityMax = %v, want 10", p.VolatilityMax)
+				}
+				if p.SortKey != "drift" {
+					t.Errorf("SortKey = %s, want drift", p.SortKey)
+				}
+			},
+		},
+		{
+			name: "drift_min > drift_max",
+			query: url.Values{
+				"drift_min": []string{"5"},
+				"drift_max": []string{"-5"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "drift_min must be <= drift_max",
+		},
+		{
+			name: "peak era filters and sort",
+			query: url.Values{
+				"era_start_min": []string{"2000"},
+				"peak_year_max": []string{"2020"},
+				"sort_key":      []string{"peak_share"},
+			},
+			dbStart: 1880,
+			dbEnd:   2024,
+			wantErr: false,
+			checkFunc: func(t *testing.T, p *NamesListParams) {
+				if p.EraStartMin == nil || *p.EraStartMin != 2000 {
+					t.Errorf("EraStartMin = %v, want 2000", p.EraStartMin)
+				}
+				if p.PeakYearMax == nil || *p.PeakYearMax != 2020 {
+					t.Errorf("PeakYearMax = %v, want 2020", p.PeakYearMax)
+				}
+				if p.PeakYearMin != nil || p.EraEndMax != nil {
+					t.Errorf("unset peak filters = %v, %v, want nil", p.PeakYearMin, p.EraEndMax)
+				}
+				if p.SortKey != "peak_share" {
+					t.Errorf("SortKey = %s, want peak_share", p.SortKey)
+				}
+			},
+		},
+		{
+			name: "peak_year_min > peak_year_max",
+			query: url.Values{
+				"peak_year_min": []string{"2000"},
+				"peak_year_max": []string{"1990"},
+			},
+			dbStart: 1880,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "peak_year_min must be <= peak_year_max",
+		},
+		{
+			name: "era_start_min not an integer",
+			query: url.Values{
+				"era_start_min": []string{"1990s"},
+			},
+			dbStart: 1880,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "era_start_min must be an integer",
+		},
+		{
+			name: "cursor mode",
+			query: url.Values{
+				"cursor": []string{NamesCursor{SortKey: "popularity", SortOrder: "asc", TotalCount: 10, Name: "Ada", Rank: 10001}.Encode()},
+			},
+			dbStart: 1880,
+			dbEnd:   2024,
+			wantErr: false,
+			checkFunc: func(t *testing.T, p *NamesListParams) {
+				if p.Cursor == nil || p.Cursor.Name != "Ada" || p.Cursor.Rank != 10001 {
+					t.Errorf("Cursor = %+v, want Ada at rank 10001", p.Cursor)
+				}
+			},
+		},
+		{
+			name: "cursor for another sort",
+			query: url.Values{
+				"cursor":   []string{NamesCursor{SortKey: "popularity", SortOrder: "asc", Name: "Ada"}.Encode()},
+				"sort_key": []string{"name"},
+			},
+			dbStart: 1880,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "cursor does not match sort_key and sort_order",
+		},
+		{
+			name: "cursor with page",
+			query: url.Values{
+				"cursor": []string{NamesCursor{SortKey: "popularity", SortOrder: "asc", Name: "Ada"}.Encode()},
+				"page":   []string{"2"},
+			},
+			dbStart: 1880,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "page cannot be combined with cursor",
+		},
+		{
+			name: "cursor garbage",
+			query: url.Values{
+				"cursor": []string{"%%%"},
+			},
+			dbStart: 1880,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "cursor is invalid",
+		},
+		{
+			name: "balance window defaults upper bound to db_end",
+			query: url.Values{
+				"balance_year_min": []string{"2022"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: false,
+			checkFunc: func(t *testing.T, p *NamesListParams) {
+				if !p.HasBalanceWindow() {
+					t.Fatalf("HasBalanceWindow() = false, want true")
+				}
+				if p.BalanceYearFrom != 2022 || p.BalanceYearTo != 2024 {
+					t.Errorf("balance window = %d-%d, want 2022-2024", p.BalanceYearFrom, p.BalanceYearTo)
+				}
+				if p.YearFrom != 2020 || p.YearTo != 2024 {
+					t.Errorf("popularity window = %d-%d, want 2020-2024", p.YearFrom, p.YearTo)
+				}
+			},
+		},
+		{
+			name:    "no balance window by default",
+			query:   url.Values{},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: false,
+			checkFunc: func(t *testing.T, p *NamesListParams) {
+				if p.HasBalanceWindow() {
+					t.Errorf("HasBalanceWindow() = true, want false")
+				}
+			},
+		},
+		{
+			name: "balance window reversed",
+			query: url.Values{
+				"balance_year_min": []string{"2024"},
+				"balance_year_max": []string{"2021"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "balance_year_min must be <= balance_year_max",
+		},
+		{
+			name: "balance scope every_country",
+			query: url.Values{
+				"countries":     []string{"US,SE"},
+				"balance_scope": []string{"every_country"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: false,
+			checkFunc: func(t *testing.T, p *NamesListParams) {
+				if p.BalanceScope != "every_country" {
+					t.Errorf("BalanceScope = %s, want every_country", p.BalanceScope)
+				}
+			},
+		},
+		{
+			name: "balance scope country requires balance_country",
+			query: url.Values{
+				"balance_scope": []string{"country"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "balance_country is required when balance_scope=country",
+		},
+		{
+			name: "balance_country outside selected countries",
+			query: url.Values{
+				"countries":       []string{"US,SE"},
+				"balance_scope":   []string{"country"},
+				"balance_country": []string{"UK"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "balance_country must be one of the selected countries",
+		},
+		{
+			name: "invalid balance scope",
+			query: url.Values{
+				"balance_scope": []string{"some"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "balance_scope must be one of",
+		},
+		{
+			name: "normalize per_births",
+			query: url.Values{
+				"normalize": []string{"per_births"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: false,
+			checkFunc: func(t *testing.T, p *NamesListParams) {
+				if p.Normalize != "per_births" {
+					t.Errorf("Normalize = %s, want per_births", p.Normalize)
+				}
+			},
+		},
+		{
+			name: "invalid normalize",
+			query: url.Values{
+				"normalize": []string{"per_capita"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "normalize must be either 'none' or 'per_births'",
+		},
+		{
+			name: "balance estimate interval",
+			query: url.Values{
+				"balance_estimate": []string{"interval"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: false,
+			checkFunc: func(t *testing.T, p *NamesListParams) {
+				if p.BalanceEstimate != "interval" {
+					t.Errorf("BalanceEstimate = %s, want interval", p.BalanceEstimate)
+				}
+			},
+		},
+		{
+			name: "invalid balance estimate",
+			query: url.Values{
+				"balance_estimate": []string{"bayes"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "balance_estimate must be either 'point' or 'interval'",
+		},
 	}
 
 	for _, tt := range tests {
@@ -281,6 +550,62 @@ func TestGetActivePopularityFilter(t *testing.T) {
 	}
 }
 
+func TestCanUseNameStats(t *testing.T) {
+	tests := []struct {
+		name  string
+		query url.Values
+		want  bool
+	}{
+		{
+			name:  "defaults cover the whole range",
+			query: url.Values{},
+			want:  true,
+		},
+		{
+			name:  "name glob and popularity filters still use stats",
+			query: url.Values{"name_glob": []string{"Al*"}, "top_n": []string{"100"}, "drift_min": []string{"1"}},
+			want:  true,
+		},
+		{
+			name:  "explicit full range",
+			query: url.Values{"year_min": []string{"1880"}, "year_max": []string{"2024"}},
+			want:  true,
+		},
+		{
+			name:  "narrower year range",
+			query: url.Values{"year_min": []string{"1990"}},
+			want:  false,
+		},
+		{
+			name:  "country filter",
+			query: url.Values{"countries": []string{"US"}},
+			want:  false,
+		},
+		{
+			name:  "per births normalization",
+			query: url.Values{"normalize": []string{"per_births"}},
+			want:  false,
+		},
+		{
+			name:  "balance window",
+			query: url.Values{"balance_year_min": []string{"2000"}},
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := ParseNamesListParams(tt.query, 1880, 2024)
+			if err != nil {
+				t.Fatalf("ParseNamesListParams() unexpected error = %v", err)
+			}
+			if got := params.CanUseNameStats(1880, 2024); got != tt.want {
+				t.Errorf("CanUseNameStats() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || containsMiddle(s, substr)))
@@ -294,3 +619,48 @@ func containsMiddle(s, substr string) bool {
 	}
 	return false
 }
+
+// postgresDB connects to the migrated database at DATABASE_URL, as CI sets
+// it, and skips the test without one
+func postgresDB(t *testing.T) *DB {
+	t.Helper()
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	db, err := New(databaseURL)
+	if err != nil {
+		t.Fatalf("New() unexpected error = %v", err)
+	}
+	t.Cleanup(db.Close)
+	return db
+}
+
+// TestNamesListQueryOnPostgres prepares and runs namesListQuery against the
+// migrated schema
+func TestNamesListQueryOnPostgres(t *testing.T) {
+	db := postgresDB(t)
+
+	ctx := context.Background()
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire() unexpected error = %v", err)
+	}
+	defer conn.Release()
+	if _, err := conn.Conn().Prepare(ctx, "names_list", namesListQuery); err != nil {
+		t.Fatalf("Prepare(namesListQuery) error = %v", err)
+	}
+
+	// Offset mode and the export stream share the query
+	params, err := ParseNamesListParams(url.Values{}, 1880, 2024)
+	if err != nil {
+		t.Fatalf("ParseNamesListParams() unexpected error = %v", err)
+	}
+	if _, err := db.GetNamesList(ctx, params); err != nil {
+		t.Errorf("GetNamesList() error = %v", err)
+	}
+	if err := db.StreamNamesList(ctx, params, func(NameRecord) error { return nil }); err != nil {
+		t.Errorf("StreamNamesList() error = %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// pooledRankScope is the name_year_ranks scope holding ranks over all countries
+const pooledRankScope = "*"
+
+// yearRank is one name_year_ranks row for the trend name
+type yearRank struct {
+	OverallRank   int
+	FemaleRank    *int
+	MaleRank      *int
+	ShareOfBirths float64
+}
+
+// rankScope returns the name_year_ranks scope matching a country selection.
+// ok is false for multi-country selections, which have no precomputed ranks.
+func rankScope(countries []string) (scope string, ok bool) {
+	switch len(countries) {
+	case 0:
+		return pooledRankScope, true
+	case 1:
+		return countries[0], true
+	default:
+		return "", false
+	}
+}
+
+// getYearRanks returns the precomputed ranks of the trend name per year.
+// The result is nil when the country selection has no precomputed ranks.
+func (db *DB) getYearRanks(ctx context.Context, params *NameTrendParams) (map[int]yearRank, error) {
+	scope, ok := rankScope(params.Countries)
+	if !ok {
+		return nil, nil
+	}
+
+	query := `
+		SELECT year, overall_rank, female_rank, male_rank, share_of_births
+		FROM name_year_ranks
+		WHERE scope = $1
+		  AND name ILIKE $2
+		  AND year >= $3
+		  AND year <= $4
+		ORDER BY year
+	`
+
+	rows, err := db.Pool.Query(ctx, query, scope, params.Name, params.YearFrom, params.YearTo)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	ranks := make(map[int]yearRank)
+	for rows.Next() {
+		var year int
+		var yr yearRank
+		if err := rows.Scan(&year, &yr.OverallRank, &yr.FemaleRank, &yr.MaleRank, &yr.ShareOfBirths); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		ranks[year] = yr
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows failed: %w", err)
+	}
+
+	return ranks, nil
+}
+
+// applyYearRanks copies per-year ranks onto a yearly time series. Years the
+// name is absent from get a zero share and no rank.
+func applyYearRanks(timeSeries []TimeSeriesPoint, ranks map[int]yearRank) {
+	if ranks == nil {
+		return
+	}
+	for i := range timeSeries {
+		ts := &timeSeries[i]
+		yr, ok := ranks[ts.Year]
+		if !ok {
+			if ts.Status == PointStatusZero {
+				zero := 0.0
+				ts.ShareOfBirths = &zero
+			}
+			continue
+		}
+		overall := yr.OverallRank
+		share := yr.ShareOfBirths
+		ts.Rank = &overall
+		ts.FemaleRank = yr.FemaleRank
+		ts.MaleRank = yr.MaleRank
+		ts.ShareOfBirths = &share
+	}
+}
@@ -0,0 +1,50 @@
+package db
+
+import "testing"
+
+func TestRankScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		countries []string
+		wantScope string
+		wantOK    bool
+	}{
+		{"all countries", nil, pooledRankScope, true},
+		{"single country", []string{"US"}, "US", true},
+		{"several countries", []string{"US", "UK"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, ok := rankScope(tt.countries)
+			if scope != tt.wantScope || ok != tt.wantOK {
+				t.Errorf("rankScope() = (%q, %v), want (%q, %v)", scope, ok, tt.wantScope, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestApplyYearRanks(t *testing.T) {
+	femaleRank := 3
+	timeSeries := []TimeSeriesPoint{
+		{Year: 2000, Status: PointStatusData},
+		{Year: 2001, Status: PointStatusZero},
+		{Year: 2002, Status: PointStatusUncovered},
+	}
+	ranks := map[int]yearRank{
+		2000: {OverallRank: 45, FemaleRank: &femaleRank, ShareOfBirths: 0.002},
+	}
+
+	applyYearRanks(timeSeries, ranks)
+
+	if p := timeSeries[0]; p.Rank == nil || *p.Rank != 45 || p.FemaleRank == nil || *p.FemaleRank != 3 ||
+		p.MaleRank != nil || p.ShareOfBirths == nil || *p.ShareOfBirths != 0.002 {
+		t.Errorf("ranked year = %+v, want rank 45, female rank 3, share 0.002", p)
+	}
+	if p := timeSeries[1]; p.Rank != nil || p.ShareOfBirths == nil || *p.ShareOfBirths != 0 {
+		t.Errorf("zero year = %+v, want no rank and zero share", p)
+	}
+	if p := timeSeries[2]; p.Rank != nil || p.ShareOfBirths != nil {
+		t.Errorf("uncovered year = %+v, want no rank or share", p)
+	}
+}
@@ -0,0 +1,28 @@
+package db
+
+import "context"
+
+// Store is the read API the handlers serve from. *DB implements it over
+// PostgreSQL; snapshot.Reader and memstore.Store answer the same queries
+// from a SQLite file and from memory.
+type Store interface {
+	Ping(ctx context.Context) error
+	CacheStats() *CacheStats
+	DataVersion(ctx context.Context) (int64, error)
+
+	GetYearRange(ctx context.Context) (*YearRange, error)
+	GetCountries(ctx context.Context) (*CountriesResponse, error)
+	GetDatasetVersions(ctx context.Context) ([]DatasetVersion, error)
+
+	GetNamesList(ctx context.Context, params *NamesListParams) (*NamesListResponse, error)
+	StreamNamesList(ctx context.Context, params *NamesListParams, emit func(NameRecord) error) error
+	GetNameTrend(ctx context.Context, params *NameTrendParams) (*NameTrendResponse, error)
+	StreamTrendExport(ctx context.Context, params *TrendExportParams, emit func(TrendExportRow) error) error
+
+	GetTrendingNames(ctx context.Context, params *TrendingParams) (*TrendingResponse, error)
+	GetNamesCompare(ctx context.Context, params *CompareParams) (*CompareResponse, error)
+	GetAgeDistribution(ctx context.Context, params *AgeDistributionParams) (*AgeDistributionResponse, error)
+	GetSimilarTrajectories(ctx context.Context, params *SimilarTrajectoryParams) (*SimilarTrajectoryResponse, error)
+}
+
+var _ Store = (*DB)(nil)
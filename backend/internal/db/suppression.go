@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// SuppressedCounts holds the largest number of births a source may have
+// omitted for one year because they fell below its publication threshold
+type SuppressedCounts struct {
+	FemaleMax int
+	MaleMax   int
+}
+
+// SuppressedBalanceBounds returns the range the gender balance can take when
+// up to maleHidden male and femaleHidden female births were suppressed.
+// ok is false when no combination yields binary gender data.
+func SuppressedBalanceBounds(maleCount, femaleCount, maleHidden, femaleHidden int) (low, high float64, ok bool) {
+	// Lowest balance: every hidden female birth existed, no hidden male ones
+	lowDenom := maleCount + femaleCount + femaleHidden
+	// Highest balance: every hidden male birth existed, no hidden female ones
+	highDenom := maleCount + maleHidden + femaleCount
+	if lowDenom == 0 || highDenom == 0 {
+		return 0, 0, false
+	}
+
+	low = 100.0 * float64(maleCount) / float64(lowDenom)
+	high = 100.0 * float64(maleCount+maleHidden) / float64(highDenom)
+
+	return low, high, true
+}
+
+// getSuppressedCounts returns, per year, how many female and male births of
+// the name may be missing because a covering dataset suppresses small counts.
+// A sex counts as suppressed in a country-year when the dataset covers that
+// year but has no row for the name and sex.
+func (db *DB) getSuppressedCounts(ctx context.Context, params *NameTrendParams, countries interface{}) (map[int]SuppressedCounts, error) {
+	query := `
+		WITH
+		coverage AS (
+			SELECT
+				d.country_id,
+				gs.year,
+				MAX(d.suppression_threshold) as threshold
+			FROM name_datasets d
+			JOIN countries c ON d.country_id = c.id
+			CROSS JOIN LATERAL generate_series(d.year_from, d.year_to) as gs(year)
+			WHERE d.parse_status = 'parsed'
+			  AND d.suppression_threshold > 0
+			  AND gs.year >= $2
+			  AND gs.year <= $3
+			  AND ($4::text[] IS NULL OR c.code = ANY($4::text[]))
+			GROUP BY d.country_id, gs.year
+		),
+		present AS (
+			SELECT
+				n.country_id,
+				n.year,
+				bool_or(n.gender = 'F') as has_female,
+				bool_or(n.gender = 'M') as has_male
+			FROM names n
+			JOIN countries c ON n.country_id = c.id
+			WHERE n.name ILIKE $1
+			  AND n.year >= $2
+			  AND n.year <= $3
+			  AND ($4::text[] IS NULL OR c.code = ANY($4::text[]))
+			GROUP BY n.country_id, n.year
+		)
+		SELECT
+			cv.year,
+			SUM(CASE WHEN COALESCE(p.has_female, false) THEN 0 ELSE cv.threshold - 1 END) as female_max,
+			SUM(CASE WHEN COALESCE(p.has_male, false) THEN 0 ELSE cv.threshold - 1 END) as male_max
+		FROM coverage cv
+		LEFT JOIN present p ON p.country_id = cv.country_id AND p.year = cv.year
+		GROUP BY cv.year
+		ORDER BY cv.year
+	`
+
+	rows, err := db.Pool.Query(ctx, query,
+		params.Name, params.YearFrom, params.YearTo, countries)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int]SuppressedCounts)
+	for rows.Next() {
+		var year int
+		var sc SuppressedCounts
+		if err := rows.Scan(&year, &sc.FemaleMax, &sc.MaleMax); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		if sc.FemaleMax > 0 || sc.MaleMax > 0 {
+			result[year] = sc
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows failed: %w", err)
+	}
+
+	return result, nil
+}
+
+// ApplySuppression flags censored time-series points and, in bounds mode,
+// fills the imputed balance range for each point and the summary
+func ApplySuppression(timeSeries []TimeSeriesPoint, summary *NameTrendSummary, suppressed map[int]SuppressedCounts, mode string) {
+	var femaleHidden, maleHidden int
+	for i := range timeSeries {
+		ts := &timeSeries[i]
+		sc, ok := suppressed[ts.Year]
+		if ok {
+			ts.Censored = true
+			ts.CensoredFemaleMax = sc.FemaleMax
+			ts.CensoredMaleMax = sc.MaleMax
+		}
+		if mode == "bounds" {
+			if low, high, ok := SuppressedBalanceBounds(ts.MaleCount, ts.FemaleCount, sc.MaleMax, sc.FemaleMax); ok {
+				ts.ImputedBalanceLow = &low
+				ts.ImputedBalanceHigh = &high
+			}
+		}
+	}
+
+	if mode != "bounds" {
+		return
+	}
+	for _, sc := range suppressed {
+		femaleHidden += sc.FemaleMax
+		maleHidden += sc.MaleMax
+	}
+	if low, high, ok := SuppressedBalanceBounds(summary.MaleCount, summary.FemaleCount, maleHidden, femaleHidden); ok {
+		summary.ImputedBalanceLow = &low
+		summary.ImputedBalanceHigh = &high
+	}
+}
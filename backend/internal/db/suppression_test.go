@@ -0,0 +1,90 @@
+package db
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSuppressedBalanceBounds(t *testing.T) {
+	tests := []struct {
+		name         string
+		male         int
+		female       int
+		maleHidden   int
+		femaleHidden int
+		wantOK       bool
+		wantLow      float64
+		wantHigh     float64
+	}{
+		{
+			name:   "nothing observed or hidden",
+			wantOK: false,
+		},
+		{
+			name:     "no suppression keeps the point estimate",
+			male:     60,
+			female:   40,
+			wantOK:   true,
+			wantLow:  60,
+			wantHigh: 60,
+		},
+		{
+			name:         "missing female row may hide up to four births",
+			male:         10,
+			female:       0,
+			femaleHidden: 4,
+			wantOK:       true,
+			wantLow:      100.0 * 10 / 14,
+			wantHigh:     100,
+		},
+		{
+			name:         "name absent in a covered year",
+			maleHidden:   4,
+			femaleHidden: 4,
+			wantOK:       true,
+			wantLow:      0,
+			wantHigh:     100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			low, high, ok := SuppressedBalanceBounds(tt.male, tt.female, tt.maleHidden, tt.femaleHidden)
+			if ok != tt.wantOK {
+				t.Fatalf("SuppressedBalanceBounds() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(low-tt.wantLow) > 1e-9 || math.Abs(high-tt.wantHigh) > 1e-9 {
+				t.Errorf("SuppressedBalanceBounds() = (%f, %f), want (%f, %f)", low, high, tt.wantLow, tt.wantHigh)
+			}
+		})
+	}
+}
+
+func TestApplySuppression(t *testing.T) {
+	timeSeries := []TimeSeriesPoint{
+		{Year: 2000, TotalCount: 100, FemaleCount: 50, MaleCount: 50},
+		{Year: 2001, TotalCount: 12, MaleCount: 12},
+	}
+	summary := NameTrendSummary{TotalCount: 112, FemaleCount: 50, MaleCount: 62}
+	suppressed := map[int]SuppressedCounts{
+		2001: {FemaleMax: 4},
+	}
+
+	ApplySuppression(timeSeries, &summary, suppressed, "bounds")
+
+	if timeSeries[0].Censored {
+		t.Errorf("2000 Censored = true, want false")
+	}
+	if !timeSeries[1].Censored || timeSeries[1].CensoredFemaleMax != 4 {
+		t.Errorf("2001 = %+v, want censored with 4 hidden female births", timeSeries[1])
+	}
+	if timeSeries[1].ImputedBalanceLow == nil || math.Abs(*timeSeries[1].ImputedBalanceLow-75) > 1e-9 {
+		t.Errorf("2001 ImputedBalanceLow = %v, want 75", timeSeries[1].ImputedBalanceLow)
+	}
+	if summary.ImputedBalanceLow == nil || math.Abs(*summary.ImputedBalanceLow-100.0*62/116) > 1e-9 {
+		t.Errorf("summary ImputedBalanceLow = %v, want %f", summary.ImputedBalanceLow, 100.0*62/116)
+	}
+}
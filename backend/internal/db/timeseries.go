@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// Time series point statuses
+const (
+	PointStatusData      = "data"      // the name has births recorded for the year
+	PointStatusZero      = "zero"      // a dataset covers the year but lists no births
+	PointStatusUncovered = "uncovered" // no dataset covers the year for the selected countries
+)
+
+// DensifyTimeSeries returns one point per year in [yearFrom, yearTo]. Years
+// missing from points become zero points marked zero or uncovered depending
+// on whether a dataset covers them.
+func DensifyTimeSeries(points []TimeSeriesPoint, yearFrom, yearTo int, covered map[int]bool) []TimeSeriesPoint {
+	byYear := make(map[int]TimeSeriesPoint, len(points))
+	for _, p := range points {
+		byYear[p.Year] = p
+	}
+
+	dense := make([]TimeSeriesPoint, 0, yearTo-yearFrom+1)
+	for year := yearFrom; year <= yearTo; year++ {
+		if p, ok := byYear[year]; ok {
+			p.Status = PointStatusData
+			dense = append(dense, p)
+			continue
+		}
+
+		status := PointStatusUncovered
+		if covered[year] {
+			status = PointStatusZero
+		}
+		dense = append(dense, TimeSeriesPoint{Year: year, Status: status})
+	}
+
+	return dense
+}
+
+// BucketTimeSeries sums a dense yearly series into buckets of interval years
+// aligned to multiples of interval (e.g. decades for 10). Each bucket is
+// labelled with its first and last year inside the series range.
+func BucketTimeSeries(points []TimeSeriesPoint, interval int, suppression string) []TimeSeriesPoint {
+	if interval <= 1 || len(points) == 0 {
+		return points
+	}
+
+	var buckets []TimeSeriesPoint
+	for _, p := range points {
+		if len(buckets) == 0 || buckets[len(buckets)-1].bucketStart(interval) != p.bucketStart(interval) {
+			buckets = append(buckets, TimeSeriesPoint{Year: p.Year, Status: PointStatusUncovered})
+		}
+
+		b := &buckets[len(buckets)-1]
+		b.YearEnd = p.Year
+		b.TotalCount += p.TotalCount
+		b.FemaleCount += p.FemaleCount
+		b.MaleCount += p.MaleCount
+		b.UnknownCount += p.UnknownCount
+		b.Censored = b.Censored || p.Censored
+		b.CensoredFemaleMax += p.CensoredFemaleMax
+		b.CensoredMaleMax += p.CensoredMaleMax
+
+		switch {
+		case p.Status == PointStatusData:
+			b.Status = PointStatusData
+		case p.Status == PointStatusZero && b.Status == PointStatusUncovered:
+			b.Status = PointStatusZero
+		}
+	}
+
+	for i := range buckets {
+		b := &buckets[i]
+		if b.MaleCount+b.FemaleCount > 0 {
+			b.GenderBalance = 100.0 * float64(b.MaleCount) / float64(b.MaleCount+b.FemaleCount)
+		}
+		b.GenderBalanceLow, b.GenderBalanceHigh, _ = GenderBalanceInterval(b.MaleCount, b.FemaleCount)
+		if suppression == "bounds" {
+			if low, high, ok := SuppressedBalanceBounds(b.MaleCount, b.FemaleCount, b.CensoredMaleMax, b.CensoredFemaleMax); ok {
+				b.ImputedBalanceLow = &low
+				b.ImputedBalanceHigh = &high
+			}
+		}
+	}
+
+	return buckets
+}
+
+// bucketStart returns the aligned start year of the bucket holding p
+func (p TimeSeriesPoint) bucketStart(interval int) int {
+	return p.Year - ((p.Year%interval)+interval)%interval
+}
+
+// getCoveredYears returns the years in the trend range that at least one
+// parsed dataset of the selected countries covers
+func (db *DB) getCoveredYears(ctx context.Context, params *NameTrendParams, countries interface{}) (map[int]bool, error) {
+	query := `
+		SELECT DISTINCT gs.year
+		FROM name_datasets d
+		JOIN countries c ON d.country_id = c.id
+		CROSS JOIN LATERAL generate_series(d.year_from, d.year_to) as gs(year)
+		WHERE d.parse_status = 'parsed'
+		  AND gs.year >= $1
+		  AND gs.year <= $2
+		  AND ($3::text[] IS NULL OR c.code = ANY($3::text[]))
+	`
+
+	rows, err := db.Pool.Query(ctx, query, params.YearFrom, params.YearTo, countries)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	covered := make(map[int]bool)
+	for rows.Next() {
+		var year int
+		if err := rows.Scan(&year); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		covered[year] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows failed: %w", err)
+	}
+
+	return covered, nil
+}
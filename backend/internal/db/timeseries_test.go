@@ -0,0 +1,77 @@
+package db
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDensifyTimeSeries(t *testing.T) {
+	points := []TimeSeriesPoint{
+		{Year: 2001, TotalCount: 10, FemaleCount: 10},
+	}
+	covered := map[int]bool{2001: true, 2002: true}
+
+	got := DensifyTimeSeries(points, 2000, 2002, covered)
+	want := []struct {
+		year   int
+		status string
+		total  int
+	}{
+		{2000, PointStatusUncovered, 0},
+		{2001, PointStatusData, 10},
+		{2002, PointStatusZero, 0},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("DensifyTimeSeries() returned %d points, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i].Year != w.year || got[i].Status != w.status || got[i].TotalCount != w.total {
+			t.Errorf("point %d = {%d %s %d}, want {%d %s %d}",
+				i, got[i].Year, got[i].Status, got[i].TotalCount, w.year, w.status, w.total)
+		}
+	}
+}
+
+func TestBucketTimeSeries(t *testing.T) {
+	points := []TimeSeriesPoint{
+		{Year: 1998, Status: PointStatusUncovered},
+		{Year: 1999, Status: PointStatusZero},
+		{Year: 2000, Status: PointStatusData, TotalCount: 4, FemaleCount: 1, MaleCount: 3},
+		{Year: 2001, Status: PointStatusZero},
+		{Year: 2009, Status: PointStatusData, TotalCount: 4, FemaleCount: 3, MaleCount: 1, Censored: true, CensoredMaleMax: 4},
+		{Year: 2010, Status: PointStatusUncovered},
+	}
+
+	got := BucketTimeSeries(points, 10, "none")
+	if len(got) != 3 {
+		t.Fatalf("BucketTimeSeries() returned %d buckets, want 3", len(got))
+	}
+
+	tests := []struct {
+		year, yearEnd int
+		status        string
+		total         int
+		balance       float64
+		censored      bool
+	}{
+		{1998, 1999, PointStatusZero, 0, 0, false},
+		{2000, 2009, PointStatusData, 8, 50, true},
+		{2010, 2010, PointStatusUncovered, 0, 0, false},
+	}
+	for i, tt := range tests {
+		b := got[i]
+		if b.Year != tt.year || b.YearEnd != tt.yearEnd || b.Status != tt.status ||
+			b.TotalCount != tt.total || math.Abs(b.GenderBalance-tt.balance) > 1e-9 || b.Censored != tt.censored {
+			t.Errorf("bucket %d = %+v, want year %d-%d status %s total %d balance %v censored %v",
+				i, b, tt.year, tt.yearEnd, tt.status, tt.total, tt.balance, tt.censored)
+		}
+	}
+	if got[1].CensoredMaleMax != 4 {
+		t.Errorf("bucket 1 CensoredMaleMax = %d, want 4", got[1].CensoredMaleMax)
+	}
+
+	if yearly := BucketTimeSeries(points, 1, "none"); len(yearly) != len(points) {
+		t.Errorf("BucketTimeSeries(interval=1) returned %d points, want %d", len(yearly), len(points))
+	}
+}
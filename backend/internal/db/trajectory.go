@@ -0,0 +1,297 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrNameNotFound is returned when a name has no precomputed trajectory
+var ErrNameNotFound = errors.New("name not found")
+
+// dtwWindow is the Sakoe-Chiba band for DTW, in decades: a decade may be
+// matched against one at most this far away
+const dtwWindow = 2
+
+// Trajectory is a name's precomputed decade vectors
+type Trajectory struct {
+	Name          string
+	TotalCount    int
+	ShareVector   []float64 // per-decade share, peak decade = 1
+	BalanceVector []float64 // per-decade gender balance, NaN without binary data
+}
+
+type TrajectoryMatch struct {
+	Name            string  `json:"name"`
+	Distance        float64 `json:"distance"`
+	ShapeDistance   float64 `json:"shape_distance"`
+	BalanceDistance float64 `json:"balance_distance"`
+	TotalCount      int     `json:"total_count"`
+	PeakDecade      int     `json:"peak_decade"`
+}
+
+type SimilarTrajectoryMeta struct {
+	Metric        string  `json:"metric"`
+	BalanceWeight float64 `json:"balance_weight"`
+	FirstDecade   int     `json:"first_decade"`
+	Decades       int     `json:"decades"`
+}
+
+type SimilarTrajectoryResponse struct {
+	Name       string                `json:"name"`
+	Meta       SimilarTrajectoryMeta `json:"meta"`
+	PeakDecade int                   `json:"peak_decade"`
+	Matches    []TrajectoryMatch     `json:"matches"`
+}
+
+type SimilarTrajectoryParams struct {
+	Name          string
+	Metric        string  // cosine, dtw
+	BalanceWeight float64 // 0 = shape only, 1 = balance only
+	MinCount      int     // candidates need at least this many births
+	Limit         int
+}
+
+func ParseSimilarTrajectoryParams(name string, query url.Values) (*SimilarTrajectoryParams, error) {
+	params := &SimilarTrajectoryParams{
+		// Defaults
+		Name:          name,
+		Metric:        "cosine",
+		BalanceWeight: 0.5,
+		MinCount:      100,
+		Limit:         10,
+	}
+
+	// Parse metric
+	if v := query.Get("metric"); v != "" {
+		params.Metric = v
+	}
+
+	// Parse balance_weight
+	if v := query.Get("balance_weight"); v != "" {
+		val, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("balance_weight must be a number")
+		}
+		params.BalanceWeight = val
+	}
+
+	// Parse min_count
+	if v := query.Get("min_count"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("min_count must be an integer")
+		}
+		params.MinCount = val
+	}
+
+	// Parse limit
+	if v := query.Get("limit"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("limit must be an integer")
+		}
+		params.Limit = val
+	}
+
+	// Validate
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+func (p *SimilarTrajectoryParams) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if p.Metric != "cosine" && p.Metric != "dtw" {
+		return fmt.Errorf("metric must be either 'cosine' or 'dtw'")
+	}
+	if math.IsNaN(p.BalanceWeight) || p.BalanceWeight < 0 || p.BalanceWeight > 1 {
+		return fmt.Errorf("balance_weight must be between 0 and 1")
+	}
+	if p.MinCount < 0 {
+		return fmt.Errorf("min_count must be >= 0")
+	}
+	if p.Limit < 1 || p.Limit > 50 {
+		return fmt.Errorf("limit must be between 1 and 50")
+	}
+
+	return nil
+}
+
+// CosineDistance returns 1 - cosine similarity of two equal-length vectors.
+// A zero vector is at distance 1 from everything.
+func CosineDistance(a, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 1
+	}
+	return 1 - dot/math.Sqrt(normA*normB)
+}
+
+// DTWDistance returns the dynamic time warping distance of two vectors with
+// absolute-difference cost, restricted to a band of window positions and
+// divided by the vector length
+func DTWDistance(a, b []float64, window int) float64 {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return 0
+	}
+	if d := n - m; d > window || -d > window {
+		window = int(math.Abs(float64(d)))
+	}
+
+	inf := math.Inf(1)
+	prev := make([]float64, m+1)
+	curr := make([]float64, m+1)
+	for j := range prev {
+		prev[j] = inf
+	}
+	prev[0] = 0
+
+	for i := 1; i <= n; i++ {
+		for j := range curr {
+			curr[j] = inf
+		}
+		for j := max(1, i-window); j <= min(m, i+window); j++ {
+			cost := math.Abs(a[i-1] - b[j-1])
+			curr[j] = cost + min(prev[j], curr[j-1], prev[j-1])
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[m] / float64(max(n, m))
+}
+
+// BalanceDistance returns the mean absolute gender balance difference over
+// decades where both names have binary data, scaled to 0-1. Names that never
+// overlap are at distance 1.
+func BalanceDistance(a, b []float64) float64 {
+	var sum float64
+	var n int
+	for i := range a {
+		if i >= len(b) || math.IsNaN(a[i]) || math.IsNaN(b[i]) {
+			continue
+		}
+		sum += math.Abs(a[i] - b[i])
+		n++
+	}
+	if n == 0 {
+		return 1
+	}
+	return sum / float64(n) / 100
+}
+
+// TrajectoryDistance combines the popularity shape and gender balance
+// distances of two trajectories
+func TrajectoryDistance(target, candidate *Trajectory, metric string, balanceWeight float64) (distance, shape, balance float64) {
+	if metric == "dtw" {
+		shape = DTWDistance(target.ShareVector, candidate.ShareVector, dtwWindow)
+	} else {
+		shape = CosineDistance(target.ShareVector, candidate.ShareVector)
+	}
+	balance = BalanceDistance(target.BalanceVector, candidate.BalanceVector)
+	distance = (1-balanceWeight)*shape + balanceWeight*balance
+
+	return distance, shape, balance
+}
+
+// peakIndex returns the position of the largest value
+func peakIndex(v []float64) int {
+	peak := 0
+	for i := range v {
+		if v[i] > v[peak] {
+			peak = i
+		}
+	}
+	return peak
+}
+
+func (db *DB) GetSimilarTrajectories(ctx context.Context, params *SimilarTrajectoryParams) (*SimilarTrajectoryResponse, error) {
+	query := `
+		SELECT name, first_decade, total_count, share_vector, balance_vector
+		FROM name_trajectories
+		WHERE name ILIKE $1
+		LIMIT 1
+	`
+
+	var target Trajectory
+	var firstDecade int
+	err := db.Pool.QueryRow(ctx, query, params.Name).Scan(
+		&target.Name, &firstDecade, &target.TotalCount, &target.ShareVector, &target.BalanceVector)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNameNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("target query failed: %w", err)
+	}
+
+	candidatesQuery := `
+		SELECT name, total_count, share_vector, balance_vector
+		FROM name_trajectories
+		WHERE total_count >= $1
+		  AND name <> $2
+	`
+
+	rows, err := db.Pool.Query(ctx, candidatesQuery, params.MinCount, target.Name)
+	if err != nil {
+		return nil, fmt.Errorf("candidates query failed: %w", err)
+	}
+	defer rows.Close()
+
+	matches := []TrajectoryMatch{}
+	for rows.Next() {
+		var candidate Trajectory
+		if err := rows.Scan(&candidate.Name, &candidate.TotalCount, &candidate.ShareVector, &candidate.BalanceVector); err != nil {
+			return nil, fmt.Errorf("candidates scan failed: %w", err)
+		}
+		distance, shape, balance := TrajectoryDistance(&target, &candidate, params.Metric, params.BalanceWeight)
+		matches = append(matches, TrajectoryMatch{
+			Name:            candidate.Name,
+			Distance:        distance,
+			ShapeDistance:   shape,
+			BalanceDistance: balance,
+			TotalCount:      candidate.TotalCount,
+			PeakDecade:      firstDecade + 10*peakIndex(candidate.ShareVector),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("candidates rows failed: %w", err)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+		return matches[i].Name < matches[j].Name
+	})
+	if len(matches) > params.Limit {
+		matches = matches[:params.Limit]
+	}
+
+	return &SimilarTrajectoryResponse{
+		Name: target.Name,
+		Meta: SimilarTrajectoryMeta{
+			Metric:        params.Metric,
+			BalanceWeight: params.BalanceWeight,
+			FirstDecade:   firstDecade,
+			Decades:       len(target.ShareVector),
+		},
+		PeakDecade: firstDecade + 10*peakIndex(target.ShareVector),
+		Matches:    matches,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"math"
+	"net/url"
+	"testing"
+)
+
+func TestParseSimilarTrajectoryParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   url.Values
+		wantErr bool
+		errMsg  string
+	}{
+		{"defaults", url.Values{}, false, ""},
+		{"dtw metric", url.Values{"metric": []string{"dtw"}}, false, ""},
+		{"unknown metric", url.Values{"metric": []string{"euclid"}}, true, "metric must be either 'cosine' or 'dtw'"},
+		{"balance weight out of range", url.Values{"balance_weight": []string{"1.5"}}, true, "balance_weight must be between 0 and 1"},
+		{"balance weight not a number", url.Values{"balance_weight": []string{"half"}}, true, "balance_weight must be a number"},
+		{"limit out of range", url.Values{"limit": []string{"0"}}, true, "limit must be between 1 and 50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSimilarTrajectoryParams("Alex", tt.query)
+			if tt.wantErr {
+				if err == nil || !contains(err.Error(), tt.errMsg) {
+					t.Errorf("ParseSimilarTrajectoryParams() error = %v, want error containing %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("ParseSimilarTrajectoryParams() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
+func TestTrajectoryDistances(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"cosine identical", CosineDistance([]float64{0, 0.5, 1}, []float64{0, 0.5, 1}), 0},
+		{"cosine orthogonal", CosineDistance([]float64{1, 0}, []float64{0, 1}), 1},
+		{"cosine zero vector", CosineDistance([]float64{0, 0}, []float64{0, 1}), 1},
+		{"dtw identical", DTWDistance([]float64{0, 1, 0}, []float64{0, 1, 0}, 2), 0},
+		{"dtw absorbs a one-decade shift", DTWDistance([]float64{0, 1, 0, 0}, []float64{0, 0, 1, 0}, 2), 0},
+		{"dtw outside the band", DTWDistance([]float64{1, 0, 0, 0}, []float64{0, 0, 0, 1}, 1), 0.5},
+		{"balance skips missing decades", BalanceDistance([]float64{nan, 40, 60}, []float64{50, 60, nan}), 0.2},
+		{"balance without overlap", BalanceDistance([]float64{nan, 40}, []float64{50, nan}), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if math.Abs(tt.got-tt.want) > 1e-9 {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
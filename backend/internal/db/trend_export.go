@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MaxTrendExportNames caps the names of one trend export
+const MaxTrendExportNames = 50
+
+// TrendExportParams selects the names and range of a trend export
+type TrendExportParams struct {
+	Names     []string // Required, matched case-insensitively
+	YearFrom  int
+	YearTo    int
+	Countries []string // empty = all countries
+}
+
+// TrendExportRow is the count of one name in one country and year, with the
+// dataset it was read from
+type TrendExportRow struct {
+	Name         string
+	CountryCode  string
+	Year         int
+	TotalCount   int
+	FemaleCount  int
+	MaleCount    int
+	UnknownCount int
+
+	DatasetID      int
+	SourceName     string // statistical agency, e.g. "Social Security Administration"
+	SourceURL      string
+	SourceFileName string
+}
+
+// Citation returns a one-line reference to the dataset the row comes from
+func (r TrendExportRow) Citation() string {
+	return fmt.Sprintf("%s (%s), %d, file %s, %s", r.SourceName, r.CountryCode, r.Year, r.SourceFileName, r.SourceURL)
+}
+
+func ParseTrendExportParams(query url.Values, dbStart, dbEnd int) (*TrendExportParams, error) {
+	params := &TrendExportParams{
+		// Defaults
+		YearFrom:  dbStart,
+		YearTo:    dbEnd,
+		Countries: []string{}, // empty = all countries
+	}
+
+	// Parse names (comma-separated, duplicates ignored case-insensitively)
+	params.Names = parseNameList(query.Get("names"))
+
+	// Parse year_min
+	if v := query.Get("year_min"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("year_min must be an integer")
+		}
+		params.YearFrom = val
+	}
+
+	// Parse year_max
+	if v := query.Get("year_max"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("year_max must be an integer")
+		}
+		params.YearTo = val
+	}
+
+	// Parse countries (comma-separated)
+	if v := query.Get("countries"); v != "" {
+		params.Countries = strings.Split(v, ",")
+	}
+
+	// Validate
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+func (p *TrendExportParams) Validate() error {
+	if len(p.Names) < 1 || len(p.Names) > MaxTrendExportNames {
+		return fmt.Errorf("names must list between 1 and %d distinct names", MaxTrendExportNames)
+	}
+	if p.YearFrom > p.YearTo {
+		return fmt.Errorf("year_min must be <= year_max")
+	}
+
+	return nil
+}
+
+// FilterValues returns the effective parameters as query parameters
+func (p *TrendExportParams) FilterValues() url.Values {
+	v := url.Values{}
+	v.Set("names", strings.Join(p.Names, ","))
+	v.Set("year_min", strconv.Itoa(p.YearFrom))
+	v.Set("year_max", strconv.Itoa(p.YearTo))
+	if len(p.Countries) > 0 {
+		v.Set("countries", strings.Join(p.Countries, ","))
+	}
+	return v
+}
+
+// likeEscaper escapes the LIKE wildcards and the escape character, so a
+// pattern matches only the literal text
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// StreamTrendExport calls emit for every (name, country, year) with births,
+// ordered by name, country and year, as rows arrive
+func (db *DB) StreamTrendExport(ctx context.Context, params *TrendExportParams, emit func(TrendExportRow) error) error {
+	query := `
+		SELECT
+			n.name,
+			c.code,
+			n.year,
+			SUM(n.count) as total_count,
+			SUM(CASE WHEN n.gender = 'F' THEN n.count ELSE 0 END) as female_count,
+			SUM(CASE WHEN n.gender = 'M' THEN n.count ELSE 0 END) as male_count,
+			SUM(CASE WHEN n.gender = 'U' THEN n.count ELSE 0 END) as unknown_count,
+			d.id,
+			c.data_source_name,
+			COALESCE(d.source_url, c.data_source_url),
+			d.source_file_name
+		FROM names n
+		JOIN countries c ON n.country_id = c.id
+		JOIN name_datasets d ON n.dataset_id = d.id
+		WHERE n.name ILIKE ANY($1::text[])
+		  AND n.year >= $2
+		  AND n.year <= $3
+		  AND ($4::text[] IS NULL OR c.code = ANY($4::text[]))
+		GROUP BY n.name, c.code, n.year, d.id, c.data_source_name, c.data_source_url
+		ORDER BY LOWER(n.name), n.name, c.code, n.year, d.id
+	`
+
+	// Handle country filter (nil for all countries)
+	var countries interface{}
+	if len(params.Countries) == 0 {
+		countries = nil
+	} else {
+		countries = params.Countries
+	}
+
+	// Names match exactly, without case: ILIKE with the wildcards escaped,
+	// which the trigram index serves
+	names := make([]string, len(params.Names))
+	for i, name := range params.Names {
+		names[i] = likeEscaper.Replace(name)
+	}
+
+	rows, err := db.Pool.Query(ctx, query, names, params.YearFrom, params.YearTo, countries)
+	if err != nil {
+		return fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r TrendExportRow
+		err := rows.Scan(
+			&r.Name,
+			&r.CountryCode,
+			&r.Year,
+			&r.TotalCount,
+			&r.FemaleCount,
+			&r.MaleCount,
+			&r.UnknownCount,
+			&r.DatasetID,
+			&r.SourceName,
+			&r.SourceURL,
+			&r.SourceFileName,
+		)
+		if err != nil {
+			return fmt.Errorf("scan failed: %w", err)
+		}
+		if err := emit(r); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseTrendExportParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   url.Values
+		wantErr string
+		check   func(t *testing.T, p *TrendExportParams)
+	}{
+		{
+			name:  "single name with defaults",
+			query: url.Values{"names": []string{"Alex"}},
+			check: func(t *testing.T, p *TrendExportParams) {
+				if len(p.Names) != 1 || p.YearFrom != 1880 || p.YearTo != 2024 || len(p.Countries) != 0 {
+					t.Errorf("params = %+v", p)
+				}
+			},
+		},
+		{
+			name:  "duplicates dropped",
+			query: url.Values{"names": []string{"Alex, alex,Sam,"}, "countries": []string{"US,SE"}},
+			check: func(t *testing.T, p *TrendExportParams) {
+				if len(p.Names) != 2 || p.Names[1] != "Sam" || len(p.Countries) != 2 {
+					t.Errorf("params = %+v", p)
+				}
+			},
+		},
+		{
+			name:    "names required",
+			query:   url.Values{},
+			wantErr: "names must list between 1 and 50 distinct names",
+		},
+		{
+			name:    "reversed years",
+			query:   url.Values{"names": []string{"Alex"}, "year_min": []string{"2000"}, "year_max": []string{"1990"}},
+			wantErr: "year_min must be <= year_max",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := ParseTrendExportParams(tt.query, 1880, 2024)
+			if tt.wantErr != "" {
+				if err == nil || !contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseTrendExportParams() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTrendExportParams() unexpected error = %v", err)
+			}
+			tt.check(t, params)
+		})
+	}
+}
+
+func TestTrendExportRowCitation(t *testing.T) {
+	r := TrendExportRow{
+		CountryCode:    "US",
+		Year:           1990,
+		SourceName:     "Social Security Administration",
+		SourceURL:      "https://www.ssa.gov/oact/babynames/limits.html",
+		SourceFileName: "yob1990.txt",
+	}
+	want := "Social Security Administration (US), 1990, file yob1990.txt, https://www.ssa.gov/oact/babynames/limits.html"
+	if got := r.Citation(); got != want {
+		t.Errorf("Citation() = %q, want %q", got, want)
+	}
+}
+
+func TestLikeEscaper(t *testing.T) {
+	tests := map[string]string{
+		"Emma":      "Emma",
+		"Em%":       `Em\%`,
+		"_mma":      `\_mma`,
+		`Em\ma`:     `Em\\ma`,
+		`100%_\%\_`: `100\%\_\\\%\\\_`,
+	}
+	for name, want := range tests {
+		if got := likeEscaper.Replace(name); got != want {
+			t.Errorf("likeEscaper.Replace(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type TrendingName struct {
+	Name          string  `json:"name"`
+	RecentCount   int     `json:"recent_count"`
+	BaselineCount int     `json:"baseline_count"`
+	RecentShare   float64 `json:"recent_share"`
+	BaselineShare float64 `json:"baseline_share"`
+	ShareChange   float64 `json:"share_change"`
+	RecentRank    int     `json:"recent_rank"`
+	BaselineRank  int     `json:"baseline_rank"`
+	RankChange    int     `json:"rank_change"`
+	GenderBalance float64 `json:"gender_balance"`
+}
+
+type TrendingMeta struct {
+	DbStart      int `json:"db_start"`
+	DbEnd        int `json:"db_end"`
+	RecentFrom   int `json:"recent_from"`
+	RecentTo     int `json:"recent_to"`
+	BaselineFrom int `json:"baseline_from"`
+	BaselineTo   int `json:"baseline_to"`
+}
+
+type TrendingResponse struct {
+	Meta        TrendingMeta   `json:"meta"`
+	Risers      []TrendingName `json:"risers"`
+	Fallers     []TrendingName `json:"fallers"`
+	Newcomers   []TrendingName `json:"newcomers"`
+	Disappeared []TrendingName `json:"disappeared"`
+}
+
+type TrendingParams struct {
+	// Windows: the recent window ends at YearTo and spans RecentYears,
+	// the baseline window spans BaselineYears immediately before it
+	YearTo        int
+	RecentYears   int
+	BaselineYears int
+
+	// Country filter
+	Countries []string
+
+	// Gender balance filter (0-100), evaluated over both windows combined
+	GenderBalanceMin int
+	GenderBalanceMax int
+
+	// Minimum births across both windows for a name to be considered
+	MinCount int
+
+	// Maximum names returned per category
+	Limit int
+}
+
+// RecentFrom returns the first year of the recent window
+func (p *TrendingParams) RecentFrom() int {
+	return p.YearTo - p.RecentYears + 1
+}
+
+// BaselineFrom returns the first year of the baseline window
+func (p *TrendingParams) BaselineFrom() int {
+	return p.RecentFrom() - p.BaselineYears
+}
+
+// BaselineTo returns the last year of the baseline window
+func (p *TrendingParams) BaselineTo() int {
+	return p.RecentFrom() - 1
+}
+
+func ParseTrendingParams(query url.Values, dbStart, dbEnd int) (*TrendingParams, error) {
+	params := &TrendingParams{
+		// Defaults
+		YearTo:           dbEnd,
+		RecentYears:      5,
+		BaselineYears:    10,
+		Countries:        []string{}, // empty = all countries
+		GenderBalanceMin: 0,
+		GenderBalanceMax: 100,
+		MinCount:         0,
+		Limit:            20,
+	}
+
+	// Parse year_max (end of the recent window)
+	if v := query.Get("year_max"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("year_max must be an integer")
+		}
+		params.YearTo = val
+	}
+
+	// Parse recent_years
+	if v := query.Get("recent_years"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("recent_years must be an integer")
+		}
+		params.RecentYears = val
+	}
+
+	// Parse baseline_years
+	if v := query.Get("baseline_years"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("baseline_years must be an integer")
+		}
+		params.BaselineYears = val
+	}
+
+	// Parse countries (comma-separated)
+	if v := query.Get("countries"); v != "" {
+		params.Countries = strings.Split(v, ",")
+	}
+
+	// Parse gender_balance_min
+	if v := query.Get("gender_balance_min"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("gender_balance_min must be an integer")
+		}
+		params.GenderBalanceMin = val
+	}
+
+	// Parse gender_balance_max
+	if v := query.Get("gender_balance_max"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("gender_balance_max must be an integer")
+		}
+		params.GenderBalanceMax = val
+	}
+
+	// Parse min_count
+	if v := query.Get("min_count"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("min_count must be an integer")
+		}
+		params.MinCount = val
+	}
+
+	// Parse limit
+	if v := query.Get("limit"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("limit must be an integer")
+		}
+		params.Limit = val
+	}
+
+	// Validate
+	if err := params.Validate(dbStart, dbEnd); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+func (p *TrendingParams) Validate(dbStart, dbEnd int) error {
+	// Window validation
+	if p.YearTo < dbStart || p.YearTo > dbEnd {
+		return fmt.Errorf("year_max must be between %d and %d", dbStart, dbEnd)
+	}
+	if p.RecentYears < 1 || p.RecentYears > 50 {
+		return fmt.Errorf("recent_years must be between 1 and 50")
+	}
+	if p.BaselineYears < 1 || p.BaselineYears > 100 {
+		return fmt.Errorf("baseline_years must be between 1 and 100")
+	}
+
+	// Gender balance validation
+	if p.GenderBalanceMin < 0 || p.GenderBalanceMin > 100 {
+		return fmt.Errorf("gender_balance_min must be between 0 and 100")
+	}
+	if p.GenderBalanceMax < 0 || p.GenderBalanceMax > 100 {
+		return fmt.Errorf("gender_balance_max must be between 0 and 100")
+	}
+	if p.GenderBalanceMin > p.GenderBalanceMax {
+		return fmt.Errorf("gender_balance_min must be <= gender_balance_max")
+	}
+
+	if p.MinCount < 0 {
+		return fmt.Errorf("min_count must be >= 0")
+	}
+
+	// Limit validation
+	if p.Limit < 1 || p.Limit > 100 {
+		return fmt.Errorf("limit must be between 1 and 100")
+	}
+
+	return nil
+}
+
+func (db *DB) GetTrendingNames(ctx context.Context, params *TrendingParams) (*TrendingResponse, error) {
+	query := `
+	WITH
+	-- Stage 1: Per-name counts in the recent and baseline windows
+	windowed AS (
+		SELECT
+			n.name,
+			SUM(CASE WHEN n.year >= $1 THEN n.count ELSE 0 END) as recent_count,
+			SUM(CASE WHEN n.year < $1 THEN n.count ELSE 0 END) as baseline_count,
+			SUM(CASE WHEN n.gender = 'M' THEN n.count ELSE 0 END) as male_count,
+			SUM(CASE WHEN n.gender IN ('M','F') THEN n.count ELSE 0 END) as binary_count
+		FROM names n
+		JOIN countries c ON n.country_id = c.id
+		WHERE n.year >= $2
+		  AND n.year <= $3
+		  AND ($4::text[] IS NULL OR c.code = ANY($4::text[]))
+		GROUP BY n.name
+	),
+	-- Stage 2: Shares and ranks within each window (over all names)
+	ranked AS (
+		SELECT
+			name,
+			recent_count,
+			baseline_count,
+			100.0 * male_count::float / NULLIF(binary_count, 0) as gender_balance,
+			COALESCE(recent_count::float / NULLIF(SUM(recent_count) OVER (), 0), 0) as recent_share,
+			COALESCE(baseline_count::float / NULLIF(SUM(baseline_count) OVER (), 0), 0) as baseline_share,
+			CASE WHEN recent_count > 0
+				THEN ROW_NUMBER() OVER (ORDER BY recent_count DESC, name ASC)
+			END as recent_rank,
+			CASE WHEN baseline_count > 0
+				THEN ROW_NUMBER() OVER (ORDER BY baseline_count DESC, name ASC)
+			END as baseline_rank
+		FROM windowed
+	),
+	-- Stage 3: Gender balance and minimum count filters
+	filtered AS (
+		SELECT
+			*,
+			recent_share - baseline_share as share_change
+		FROM ranked
+		WHERE (gender_balance IS NULL OR (gender_balance >= $5 AND gender_balance <= $6))
+		  AND recent_count + baseline_count >= $7
+	),
+	-- Stage 4: Categories
+	categorized AS (
+		SELECT 'riser' as category, ROW_NUMBER() OVER (ORDER BY share_change DESC, name ASC) as category_rank, *
+		FROM filtered
+		WHERE recent_count > 0 AND baseline_count > 0 AND share_change > 0
+		UNION ALL
+		SELECT 'faller', ROW_NUMBER() OVER (ORDER BY share_change ASC, name ASC), *
+		FROM filtered
+		WHERE recent_count > 0 AND baseline_count > 0 AND share_change < 0
+		UNION ALL
+		SELECT 'newcomer', ROW_NUMBER() OVER (ORDER BY recent_count DESC, name ASC), *
+		FROM filtered
+		WHERE baseline_count = 0
+		UNION ALL
+		SELECT 'disappeared', ROW_NUMBER() OVER (ORDER BY baseline_count DESC, name ASC), *
+		FROM filtered
+		WHERE recent_count = 0
+	)
+	SELECT
+		category,
+		name,
+		recent_count,
+		baseline_count,
+		recent_share,
+		baseline_share,
+		share_change,
+		recent_rank,
+		baseline_rank,
+		gender_balance
+	FROM categorized
+	WHERE category_rank <= $8
+	ORDER BY category, category_rank
+	`
+
+	// Handle country filter (nil for all countries)
+	var countries interface{}
+	if len(params.Countries) == 0 {
+		countries = nil
+	} else {
+		countries = params.Countries
+	}
+
+	rows, err := db.Pool.Query(ctx, query,
+		params.RecentFrom(),     // $1
+		params.BaselineFrom(),   // $2
+		params.YearTo,           // $3
+		countries,               // $4
+		params.GenderBalanceMin, // $5
+		params.GenderBalanceMax, // $6
+		params.MinCount,         // $7
+		params.Limit,            // $8
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	response := &TrendingResponse{
+		Risers:      []TrendingName{},
+		Fallers:     []TrendingName{},
+		Newcomers:   []TrendingName{},
+		Disappeared: []TrendingName{},
+	}
+
+	for rows.Next() {
+		var category string
+		var tn TrendingName
+		var recentRank, baselineRank *int
+		var genderBalance *float64
+
+		err := rows.Scan(
+			&category,
+			&tn.Name,
+			&tn.RecentCount,
+			&tn.BaselineCount,
+			&tn.RecentShare,
+			&tn.BaselineShare,
+			&tn.ShareChange,
+			&recentRank,
+			&baselineRank,
+			&genderBalance,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+
+		if recentRank != nil {
+			tn.RecentRank = *recentRank
+		}
+		if baselineRank != nil {
+			tn.BaselineRank = *baselineRank
+		}
+		if recentRank != nil && baselineRank != nil {
+			// Positive when the name climbed the ranking
+			tn.RankChange = *baselineRank - *recentRank
+		}
+		if genderBalance != nil {
+			tn.GenderBalance = *genderBalance
+		}
+
+		switch category {
+		case "riser":
+			response.Risers = append(response.Risers, tn)
+		case "faller":
+			response.Fallers = append(response.Fallers, tn)
+		case "newcomer":
+			response.Newcomers = append(response.Newcomers, tn)
+		case "disappeared":
+			response.Disappeared = append(response.Disappeared, tn)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows failed: %w", err)
+	}
+
+	// Get database year range for meta
+	yearRange, err := db.GetYearRange(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get year range: %w", err)
+	}
+
+	response.Meta = TrendingMeta{
+		DbStart:      yearRange.MinYear,
+		DbEnd:        yearRange.MaxYear,
+		RecentFrom:   params.RecentFrom(),
+		RecentTo:     params.YearTo,
+		BaselineFrom: params.BaselineFrom(),
+		BaselineTo:   params.BaselineTo(),
+	}
+
+	return response, nil
+}
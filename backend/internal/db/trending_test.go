@@ -0,0 +1,138 @@
+package db
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseTrendingParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     url.Values
+		dbStart   int
+		dbEnd     int
+		wantErr   bool
+		errMsg    string
+		checkFunc func(*testing.T, *TrendingParams)
+	}{
+		{
+			name:    "default windows",
+			query:   url.Values{},
+			dbStart: 1880,
+			dbEnd:   2024,
+			wantErr: false,
+			checkFunc: func(t *testing.T, p *TrendingParams) {
+				if p.RecentFrom() != 2020 {
+					t.Errorf("RecentFrom() = %d, want 2020", p.RecentFrom())
+				}
+				if p.BaselineFrom() != 2010 || p.BaselineTo() != 2019 {
+					t.Errorf("baseline = %d-%d, want 2010-2019", p.BaselineFrom(), p.BaselineTo())
+				}
+				if p.Limit != 20 {
+					t.Errorf("Limit = %d, want 20", p.Limit)
+				}
+			},
+		},
+		{
+			name: "custom windows",
+			query: url.Values{
+				"year_max":       []string{"2000"},
+				"recent_years":   []string{"10"},
+				"baseline_years": []string{"20"},
+			},
+			dbStart: 1880,
+			dbEnd:   2024,
+			wantErr: false,
+			checkFunc: func(t *testing.T, p *TrendingParams) {
+				if p.RecentFrom() != 1991 {
+					t.Errorf("RecentFrom() = %d, want 1991", p.RecentFrom())
+				}
+				if p.BaselineFrom() != 1971 || p.BaselineTo() != 1990 {
+					t.Errorf("baseline = %d-%d, want 1971-1990", p.BaselineFrom(), p.BaselineTo())
+				}
+			},
+		},
+		{
+			name: "year_max outside database range",
+			query: url.Values{
+				"year_max": []string{"2030"},
+			},
+			dbStart: 1880,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "year_max must be between 1880 and 2024",
+		},
+		{
+			name: "invalid recent_years",
+			query: url.Values{
+				"recent_years": []string{"0"},
+			},
+			dbStart: 1880,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "recent_years must be between 1 and 50",
+		},
+		{
+			name: "baseline_years not integer",
+			query: url.Values{
+				"baseline_years": []string{"ten"},
+			},
+			dbStart: 1880,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "baseline_years must be an integer",
+		},
+		{
+			name: "gender balance band and countries",
+			query: url.Values{
+				"gender_balance_min": []string{"40"},
+				"gender_balance_max": []string{"60"},
+				"countries":          []string{"US,SE"},
+			},
+			dbStart: 1880,
+			dbEnd:   2024,
+			wantErr: false,
+			checkFunc: func(t *testing.T, p *TrendingParams) {
+				if p.GenderBalanceMin != 40 || p.GenderBalanceMax != 60 {
+					t.Errorf("balance band = %d-%d, want 40-60", p.GenderBalanceMin, p.GenderBalanceMax)
+				}
+				if len(p.Countries) != 2 {
+					t.Errorf("Countries length = %d, want 2", len(p.Countries))
+				}
+			},
+		},
+		{
+			name: "limit out of range",
+			query: url.Values{
+				"limit": []string{"500"},
+			},
+			dbStart: 1880,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "limit must be between 1 and 100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := ParseTrendingParams(tt.query, tt.dbStart, tt.dbEnd)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseTrendingParams() error = nil, want error containing %q", tt.errMsg)
+					return
+				}
+				if tt.errMsg != "" && !contains(err.Error(), tt.errMsg) {
+					t.Errorf("ParseTrendingParams() error = %v, want error containing %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("ParseTrendingParams() unexpected error = %v", err)
+				return
+			}
+			if tt.checkFunc != nil {
+				tt.checkFunc(t, params)
+			}
+		})
+	}
+}
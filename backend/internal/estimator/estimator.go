@@ -0,0 +1,166 @@
+// Package estimator estimates the gender balance of names that have no
+// recorded births, using naive Bayes over character n-grams and endings.
+package estimator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// ModelType identifies the estimator in API responses
+const ModelType = "char-ngram-naive-bayes"
+
+// modelVersion is bumped whenever the feature set changes, so stale model
+// files are rejected instead of silently mis-scored
+const modelVersion = 1
+
+// Laplace smoothing added to every feature count
+const smoothing = 1.0
+
+// Example is one training name with its observed births by sex
+type Example struct {
+	Name        string
+	FemaleCount int
+	MaleCount   int
+}
+
+// Model holds per-class feature weights. Each training name contributes
+// its female and male fractions of births, so every name weighs the same
+// regardless of popularity.
+type Model struct {
+	Version   int                   `json:"version"`
+	TrainedAt time.Time             `json:"trained_at"`
+	Names     int                   `json:"names"`
+	Features  map[string][2]float64 `json:"features"` // feature -> [female, male] weight
+	Totals    [2]float64            `json:"totals"`   // summed feature weight per class
+}
+
+// Estimate is a modelled gender balance for one name
+type Estimate struct {
+	// Estimated share of male births (0-100), same axis as observed balances
+	GenderBalance float64 `json:"gender_balance"`
+	// Share of the name's features seen in training (0-1)
+	Confidence    float64 `json:"confidence"`
+	KnownFeatures int     `json:"known_features"`
+	TotalFeatures int     `json:"total_features"`
+}
+
+// Features returns the character 2-4 grams of a name (with ^ and $ marking
+// its start and end) plus its 1-3 letter endings
+func Features(name string) []string {
+	runes := []rune("^" + strings.ToLower(strings.TrimSpace(name)) + "$")
+	var features []string
+	for n := 2; n <= 4; n++ {
+		for i := 0; i+n <= len(runes); i++ {
+			features = append(features, string(runes[i:i+n]))
+		}
+	}
+
+	letters := runes[1 : len(runes)-1]
+	for n := 1; n <= 3 && n <= len(letters); n++ {
+		features = append(features, "end:"+string(letters[len(letters)-n:]))
+	}
+
+	return features
+}
+
+// Train builds a model from names with observed births. Names without
+// binary gender data are skipped.
+func Train(examples []Example) *Model {
+	m := &Model{
+		Version:   modelVersion,
+		TrainedAt: time.Now().UTC(),
+		Features:  make(map[string][2]float64),
+	}
+
+	for _, ex := range examples {
+		binary := ex.FemaleCount + ex.MaleCount
+		if binary == 0 {
+			continue
+		}
+		weights := [2]float64{
+			float64(ex.FemaleCount) / float64(binary),
+			float64(ex.MaleCount) / float64(binary),
+		}
+		for _, f := range Features(ex.Name) {
+			w := m.Features[f]
+			w[0] += weights[0]
+			w[1] += weights[1]
+			m.Features[f] = w
+			m.Totals[0] += weights[0]
+			m.Totals[1] += weights[1]
+		}
+		m.Names++
+	}
+
+	return m
+}
+
+// Estimate scores a name. Overlapping n-grams are far from independent, so
+// the per-feature log-likelihood ratios are averaged rather than summed;
+// summing would push almost every name to 0 or 100. Class priors are equal,
+// so the estimate reflects only the spelling.
+func (m *Model) Estimate(name string) Estimate {
+	features := Features(name)
+	vocabulary := float64(len(m.Features))
+
+	var logOdds float64
+	var known int
+	for _, f := range features {
+		w, ok := m.Features[f]
+		if ok {
+			known++
+		}
+		pFemale := (w[0] + smoothing) / (m.Totals[0] + smoothing*vocabulary)
+		pMale := (w[1] + smoothing) / (m.Totals[1] + smoothing*vocabulary)
+		logOdds += math.Log(pMale) - math.Log(pFemale)
+	}
+
+	est := Estimate{
+		GenderBalance: 50,
+		KnownFeatures: known,
+		TotalFeatures: len(features),
+	}
+	if len(features) == 0 {
+		return est
+	}
+	logOdds /= float64(len(features))
+	est.GenderBalance = 100.0 / (1 + math.Exp(-logOdds))
+	est.Confidence = float64(known) / float64(len(features))
+
+	return est
+}
+
+// Save writes the model to path as JSON
+func (m *Model) Save(path string) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to encode model: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write model: %w", err)
+	}
+	return nil
+}
+
+// Load reads a model written by Save
+func Load(path string) (*Model, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model: %w", err)
+	}
+
+	var m Model
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode model: %w", err)
+	}
+	if m.Version != modelVersion {
+		return nil, fmt.Errorf("model version %d is not supported (want %d), re-run the import", m.Version, modelVersion)
+	}
+
+	return &m, nil
+}
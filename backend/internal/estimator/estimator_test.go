@@ -0,0 +1,61 @@
+package estimator
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var trainingNames = []Example{
+	{Name: "Anna", FemaleCount: 100},
+	{Name: "Maria", FemaleCount: 100},
+	{Name: "Julia", FemaleCount: 100},
+	{Name: "Mark", MaleCount: 100},
+	{Name: "Frank", MaleCount: 100},
+	{Name: "Jack", MaleCount: 100},
+	{Name: "Alex", FemaleCount: 50, MaleCount: 50},
+	{Name: "Unknown"},
+}
+
+func TestFeatures(t *testing.T) {
+	got := Features("Ana")
+	want := []string{"^a", "an", "na", "a$", "^an", "ana", "na$", "^ana", "ana$", "end:a", "end:na", "end:ana"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Features(Ana) = %v, want %v", got, want)
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	m := Train(trainingNames)
+	if m.Names != 7 {
+		t.Errorf("Names = %d, want 7 (names without binary data skipped)", m.Names)
+	}
+
+	if est := m.Estimate("Marianna"); est.GenderBalance >= 50 {
+		t.Errorf("Estimate(Marianna) balance = %.1f, want < 50", est.GenderBalance)
+	}
+	if est := m.Estimate("Franck"); est.GenderBalance <= 50 {
+		t.Errorf("Estimate(Franck) balance = %.1f, want > 50", est.GenderBalance)
+	}
+
+	est := m.Estimate("Qyzz")
+	if est.Confidence >= m.Estimate("Anna").Confidence {
+		t.Errorf("Estimate(Qyzz) confidence = %.2f, want below a training name", est.Confidence)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	m := Train(trainingNames)
+	path := filepath.Join(t.TempDir(), "model.json")
+	if err := m.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, want := loaded.Estimate("Julia"), m.Estimate("Julia"); got != want {
+		t.Errorf("loaded Estimate(Julia) = %+v, want %+v", got, want)
+	}
+}
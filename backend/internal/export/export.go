@@ -0,0 +1,217 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/supercakecrumb/nomia/internal/db"
+)
+
+// Export formats
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatJSON   = "json"
+	FormatXLSX   = "xlsx" // workbook with a "data" sheet and an "export" header sheet
+)
+
+// Header records what an export contains and which data it was computed
+// from, so the file can be reproduced later
+type Header struct {
+	Export      string              `json:"export"` // what was exported, e.g. "names"
+	GeneratedAt time.Time           `json:"generated_at"`
+	DataVersion int64               `json:"data_version"`
+	Filters     map[string]string   `json:"filters"`
+	Datasets    []db.DatasetVersion `json:"datasets"`
+}
+
+// Writer streams a header and table rows in one format. Rows are written as
+// they come; Flush pushes buffered output to the underlying writer.
+type Writer interface {
+	Begin(header Header, columns []string) error
+	Row(values []interface{}) error
+	Flush() error
+	End() error
+}
+
+// NewWriter returns a Writer for format
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	buf := bufio.NewWriter(w)
+	switch format {
+	case FormatCSV:
+		return &csvWriter{buf: buf, csv: csv.NewWriter(buf)}, nil
+	case FormatNDJSON:
+		return &jsonWriter{buf: buf, lines: true}, nil
+	case FormatJSON:
+		return &jsonWriter{buf: buf}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w), nil
+	}
+	return nil, fmt.Errorf("format must be one of: %s", strings.Join(Formats(), ", "))
+}
+
+// Formats lists the supported formats
+func Formats() []string {
+	return []string{FormatCSV, FormatNDJSON, FormatJSON, FormatXLSX}
+}
+
+// ContentType returns the MIME type of format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/json"
+}
+
+// csvWriter writes the header as leading "# key: value" comment lines
+type csvWriter struct {
+	buf *bufio.Writer
+	csv *csv.Writer
+}
+
+func (w *csvWriter) Begin(header Header, columns []string) error {
+	fmt.Fprintf(w.buf, "# export: %s\n", header.Export)
+	fmt.Fprintf(w.buf, "# generated_at: %s\n", header.GeneratedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(w.buf, "# data_version: %d\n", header.DataVersion)
+	for _, key := range sortedKeys(header.Filters) {
+		fmt.Fprintf(w.buf, "# filter %s: %s\n", key, header.Filters[key])
+	}
+	for _, d := range header.Datasets {
+		fmt.Fprintf(w.buf, "# dataset %s: %d files, %d-%d, parsed %s\n",
+			d.Country, d.Datasets, d.YearFrom, d.YearTo, d.LastParsedAt.UTC().Format(time.RFC3339))
+	}
+	return w.csv.Write(columns)
+}
+
+func (w *csvWriter) Row(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = FormatCell(v)
+	}
+	return w.csv.Write(record)
+}
+
+func (w *csvWriter) Flush() error {
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return err
+	}
+	return w.buf.Flush()
+}
+
+func (w *csvWriter) End() error {
+	return w.Flush()
+}
+
+// jsonWriter writes either one JSON document {"header": ..., "rows": [...]}
+// or, with lines set, NDJSON whose first line is {"header": ...}. Rows are
+// objects keyed by column name, in column order.
+type jsonWriter struct {
+	buf     *bufio.Writer
+	lines   bool
+	columns []string
+	rows    int
+}
+
+func (w *jsonWriter) Begin(header Header, columns []string) error {
+	w.columns = columns
+	b, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	if w.lines {
+		_, err = fmt.Fprintf(w.buf, "{\"header\":%s}\n", b)
+	} else {
+		_, err = fmt.Fprintf(w.buf, "{\"header\":%s,\"rows\":[", b)
+	}
+	return err
+}
+
+func (w *jsonWriter) Row(values []interface{}) error {
+	if !w.lines && w.rows > 0 {
+		w.buf.WriteByte(',')
+	}
+	w.rows++
+
+	w.buf.WriteByte('{')
+	for i, column := range w.columns {
+		if i > 0 {
+			w.buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(column)
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return err
+		}
+		w.buf.Write(key)
+		w.buf.WriteByte(':')
+		w.buf.Write(value)
+	}
+	w.buf.WriteByte('}')
+	if w.lines {
+		w.buf.WriteByte('\n')
+	}
+	return nil
+}
+
+func (w *jsonWriter) Flush() error {
+	return w.buf.Flush()
+}
+
+func (w *jsonWriter) End() error {
+	if !w.lines {
+		w.buf.WriteString("]}\n")
+	}
+	return w.Flush()
+}
+
+// FormatCell renders a value for text formats: nil pointers are empty,
+// floats use the shortest exact form and lists are joined with ";"
+func FormatCell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case *int:
+		if v == nil {
+			return ""
+		}
+		return strconv.Itoa(*v)
+	case *float64:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	case []string:
+		return strings.Join(v, ";")
+	}
+	return fmt.Sprint(v)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
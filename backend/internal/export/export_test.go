@@ -0,0 +1,111 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/supercakecrumb/nomia/internal/db"
+)
+
+func testHeader() Header {
+	return Header{
+		Export:      "names",
+		GeneratedAt: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+		DataVersion: 7,
+		Filters:     map[string]string{"year_min": "1990", "countries": "US,SE"},
+		Datasets: []db.DatasetVersion{
+			{Country: "US", Datasets: 2, YearFrom: 1990, YearTo: 1991, LastParsedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+}
+
+func writeAll(t *testing.T, format string, rows [][]interface{}) string {
+	t.Helper()
+	var out bytes.Buffer
+	w, err := NewWriter(format, &out)
+	if err != nil {
+		t.Fatalf("NewWriter(%q) unexpected error = %v", format, err)
+	}
+	if err := w.Begin(testHeader(), []string{"name", "count", "share", "countries"}); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	for _, row := range rows {
+		if err := w.Row(row); err != nil {
+			t.Fatalf("Row() error = %v", err)
+		}
+	}
+	if err := w.End(); err != nil {
+		t.Fatalf("End() error = %v", err)
+	}
+	return out.String()
+}
+
+var testRows = [][]interface{}{
+	{"Alex", 120, 0.25, []string{"SE", "US"}},
+	{"Ny, \"quoted\"", 5, (*float64)(nil), []string{"US"}},
+}
+
+func TestCSVWriter(t *testing.T) {
+	got := writeAll(t, FormatCSV, testRows)
+	want := `# export: names
+# generated_at: 2026-10-18T12:00:00Z
+# data_version: 7
+# filter countries: US,SE
+# filter year_min: 1990
+# dataset US: 2 files, 1990-1991, parsed 2026-10-01T00:00:00Z
+name,count,share,countries
+Alex,120,0.25,SE;US
+"Ny, ""quoted""",5,,US
+`
+	if got != want {
+		t.Errorf("CSV output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(writeAll(t, FormatNDJSON, testRows)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want header + 2 rows", len(lines))
+	}
+
+	var header struct {
+		Header Header `json:"header"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil || header.Header.DataVersion != 7 {
+		t.Errorf("header line = %s (err %v)", lines[0], err)
+	}
+	if want := `{"name":"Alex","count":120,"share":0.25,"countries":["SE","US"]}`; lines[1] != want {
+		t.Errorf("row line = %s, want %s", lines[1], want)
+	}
+	if want := `{"name":"Ny, \"quoted\"","count":5,"share":null,"countries":["US"]}`; lines[2] != want {
+		t.Errorf("row line = %s, want %s", lines[2], want)
+	}
+}
+
+func TestJSONWriter(t *testing.T) {
+	for _, rows := range [][][]interface{}{testRows, nil} {
+		var doc struct {
+			Header Header                   `json:"header"`
+			Rows   []map[string]interface{} `json:"rows"`
+		}
+		out := writeAll(t, FormatJSON, rows)
+		if err := json.Unmarshal([]byte(out), &doc); err != nil {
+			t.Fatalf("output is not valid JSON: %v\n%s", err, out)
+		}
+		if len(doc.Rows) != len(rows) {
+			t.Errorf("got %d rows, want %d", len(doc.Rows), len(rows))
+		}
+		if doc.Header.Filters["year_min"] != "1990" {
+			t.Errorf("header filters = %v", doc.Header.Filters)
+		}
+	}
+}
+
+func TestNewWriterUnknownFormat(t *testing.T) {
+	if _, err := NewWriter("xml", &bytes.Buffer{}); err == nil {
+		t.Errorf("NewWriter(xml) expected error")
+	}
+}
@@ -0,0 +1,60 @@
+package export
+
+import "github.com/supercakecrumb/nomia/internal/db"
+
+// NameColumns are the columns of a names list export, named like the
+// /api/names response fields
+var NameColumns = []string{
+	"rank",
+	"name",
+	"total_count",
+	"female_count",
+	"male_count",
+	"gender_balance",
+	"gender_balance_low",
+	"gender_balance_high",
+	"cumulative_share",
+	"name_start",
+	"name_end",
+	"countries",
+	"normalized_count",
+	"window_gender_balance",
+	"drift",
+	"early_balance",
+	"late_balance",
+	"balance_volatility",
+	"peak_year",
+	"peak_share",
+	"era_start",
+	"era_end",
+	"years_since_peak",
+}
+
+// NameRow returns the values of nr in NameColumns order
+func NameRow(nr db.NameRecord) []interface{} {
+	return []interface{}{
+		nr.Rank,
+		nr.Name,
+		nr.TotalCount,
+		nr.FemaleCount,
+		nr.MaleCount,
+		nr.GenderBalance,
+		nr.GenderBalanceLow,
+		nr.GenderBalanceHigh,
+		nr.CumulativeShare,
+		nr.NameStart,
+		nr.NameEnd,
+		nr.Countries,
+		nr.NormalizedCount,
+		nr.WindowGenderBalance,
+		nr.Drift,
+		nr.EarlyBalance,
+		nr.LateBalance,
+		nr.BalanceVolatility,
+		nr.PeakYear,
+		nr.PeakShare,
+		nr.EraStart,
+		nr.EraEnd,
+		nr.YearsSincePeak,
+	}
+}
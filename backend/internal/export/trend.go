@@ -0,0 +1,38 @@
+package export
+
+import "github.com/supercakecrumb/nomia/internal/db"
+
+// TrendColumns are the columns of a trend export: one row per name,
+// country and year, with the dataset the counts were read from
+var TrendColumns = []string{
+	"name",
+	"country",
+	"year",
+	"total_count",
+	"female_count",
+	"male_count",
+	"unknown_count",
+	"source",
+	"source_url",
+	"source_file",
+	"dataset_id",
+	"citation",
+}
+
+// TrendRow returns the values of r in TrendColumns order
+func TrendRow(r db.TrendExportRow) []interface{} {
+	return []interface{}{
+		r.Name,
+		r.CountryCode,
+		r.Year,
+		r.TotalCount,
+		r.FemaleCount,
+		r.MaleCount,
+		r.UnknownCount,
+		r.SourceName,
+		r.SourceURL,
+		r.SourceFileName,
+		r.DatasetID,
+		r.Citation(),
+	}
+}
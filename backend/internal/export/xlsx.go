@@ -0,0 +1,179 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/worksheets/sheet2.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets>
+<sheet name="data" sheetId="1" r:id="rId1"/>
+<sheet name="export" sheetId="2" r:id="rId2"/>
+</sheets>
+</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet2.xml"/>
+</Relationships>`
+
+const (
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// xlsxWriter writes the static workbook parts and the export sheet in
+// Begin, then streams rows into the data sheet, which stays the open zip
+// entry until End
+type xlsxWriter struct {
+	buf   *bufio.Writer
+	zip   *zip.Writer
+	sheet io.Writer
+	rows  int
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	buf := bufio.NewWriter(w)
+	return &xlsxWriter{buf: buf, zip: zip.NewWriter(buf)}
+}
+
+func (w *xlsxWriter) Begin(header Header, columns []string) error {
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/worksheets/sheet2.xml", xlsxHeaderSheet(header)},
+	}
+	for _, part := range parts {
+		f, err := w.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return err
+		}
+	}
+
+	sheet, err := w.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	w.sheet = sheet
+	if _, err := io.WriteString(w.sheet, xlsxSheetStart); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(columns))
+	for i, c := range columns {
+		values[i] = c
+	}
+	return w.Row(values)
+}
+
+func (w *xlsxWriter) Row(values []interface{}) error {
+	w.rows++
+	_, err := io.WriteString(w.sheet, xlsxRow(w.rows, values))
+	return err
+}
+
+func (w *xlsxWriter) Flush() error {
+	if err := w.zip.Flush(); err != nil {
+		return err
+	}
+	return w.buf.Flush()
+}
+
+func (w *xlsxWriter) End() error {
+	if _, err := io.WriteString(w.sheet, xlsxSheetEnd); err != nil {
+		return err
+	}
+	if err := w.zip.Close(); err != nil {
+		return err
+	}
+	return w.buf.Flush()
+}
+
+// xlsxHeaderSheet lays the header out as key/value rows
+func xlsxHeaderSheet(header Header) string {
+	var b strings.Builder
+	b.WriteString(xlsxSheetStart)
+
+	rows := [][]interface{}{
+		{"export", header.Export},
+		{"generated_at", header.GeneratedAt.UTC().Format(time.RFC3339)},
+		{"data_version", header.DataVersion},
+	}
+	for _, key := range sortedKeys(header.Filters) {
+		rows = append(rows, []interface{}{"filter " + key, header.Filters[key]})
+	}
+	for _, d := range header.Datasets {
+		rows = append(rows, []interface{}{
+			"dataset " + d.Country,
+			fmt.Sprintf("%d files, %d-%d, parsed %s", d.Datasets, d.YearFrom, d.YearTo, d.LastParsedAt.UTC().Format(time.RFC3339)),
+		})
+	}
+	for i, row := range rows {
+		b.WriteString(xlsxRow(i+1, row))
+	}
+
+	b.WriteString(xlsxSheetEnd)
+	return b.String()
+}
+
+// xlsxRow renders one sheet row; numbers become numeric cells, everything
+// else inline strings, and nil values are left out
+func xlsxRow(n int, values []interface{}) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<row r="%d">`, n)
+	for i, v := range values {
+		ref := xlsxColumn(i) + strconv.Itoa(n)
+		switch v := v.(type) {
+		case int, int64, float64:
+			fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, FormatCell(v))
+		case *int, *float64:
+			if text := FormatCell(v); text != "" {
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, text)
+			}
+		case nil:
+		default:
+			fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			xml.EscapeText(&b, []byte(FormatCell(v)))
+			b.WriteString(`</t></is></c>`)
+		}
+	}
+	b.WriteString(`</row>`)
+	return b.String()
+}
+
+// xlsxColumn returns the spreadsheet column letters for a 0-based index
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
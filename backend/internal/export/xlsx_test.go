@@ -0,0 +1,86 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+)
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func TestXLSXWriter(t *testing.T) {
+	out := writeAll(t, FormatXLSX, testRows)
+
+	zr, err := zip.NewReader(bytes.NewReader([]byte(out)), int64(len(out)))
+	if err != nil {
+		t.Fatalf("output is not a zip archive: %v", err)
+	}
+
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(b)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+
+	var sheet xlsxSheet
+	if err := xml.Unmarshal([]byte(parts["xl/worksheets/sheet1.xml"]), &sheet); err != nil {
+		t.Fatalf("sheet1 is not valid XML: %v", err)
+	}
+	if len(sheet.Rows) != 3 {
+		t.Fatalf("got %d rows, want column row + 2", len(sheet.Rows))
+	}
+
+	second := sheet.Rows[2]
+	if second.R != 3 || len(second.Cells) != 3 {
+		t.Fatalf("row 3 = %+v, want 3 cells (nil share left out)", second)
+	}
+	if c := second.Cells[0]; c.Ref != "A3" || c.Type != "inlineStr" || c.Inline != `Ny, "quoted"` {
+		t.Errorf("A3 = %+v", c)
+	}
+	if c := second.Cells[1]; c.Ref != "B3" || c.Type != "" || c.Value != "5" {
+		t.Errorf("B3 = %+v, want numeric 5", c)
+	}
+	if c := second.Cells[2]; c.Ref != "D3" || c.Inline != "US" {
+		t.Errorf("third cell = %+v, want D3 US", c)
+	}
+
+	var info xlsxSheet
+	if err := xml.Unmarshal([]byte(parts["xl/worksheets/sheet2.xml"]), &info); err != nil {
+		t.Fatalf("sheet2 is not valid XML: %v", err)
+	}
+	if got := info.Rows[2].Cells[1].Value; got != "7" {
+		t.Errorf("data_version cell = %q, want 7", got)
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for i, want := range tests {
+		if got := xlsxColumn(i); got != want {
+			t.Errorf("xlsxColumn(%d) = %s, want %s", i, got, want)
+		}
+	}
+}
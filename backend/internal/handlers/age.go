@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/supercakecrumb/nomia/internal/config"
+	"github.com/supercakecrumb/nomia/internal/db"
+)
+
+// NameAgeDistribution returns the estimated number and ages of living
+// bearers of a name
+func NameAgeDistribution(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get year range for defaults
+		ctx := r.Context()
+		yearRange, err := cfg.DB.GetYearRange(ctx)
+		if err != nil {
+			WriteDatabaseError(w, err)
+			return
+		}
+
+		// Parse and validate parameters
+		params, err := db.ParseAgeDistributionParams(r.URL.Query(), yearRange.MinYear, yearRange.MaxYear)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid parameters: %v", err))
+			return
+		}
+
+		// Query database
+		response, err := cfg.DB.GetAgeDistribution(ctx, params)
+		if err != nil {
+			WriteDatabaseError(w, err)
+			return
+		}
+
+		// Check if name exists
+		if response.TotalBirths == 0 {
+			WriteError(w, http.StatusNotFound, fmt.Sprintf("Name '%s' not found", params.Name))
+			return
+		}
+
+		// Return JSON response
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
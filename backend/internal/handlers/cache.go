@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/supercakecrumb/nomia/internal/config"
+)
+
+// DataVersion returns the version response ETags are derived from: the
+// data store's data version plus the loaded estimator model
+func DataVersion(cfg *config.Config) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		version, err := cfg.DB.DataVersion(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		model := "none"
+		if cfg.Estimator != nil {
+			model = cfg.Estimator.TrainedAt.Format(time.RFC3339Nano)
+		}
+
+		return fmt.Sprintf("%d/%s", version, model), nil
+	}
+}
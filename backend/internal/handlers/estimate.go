@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/supercakecrumb/nomia/internal/config"
+	"github.com/supercakecrumb/nomia/internal/estimator"
+)
+
+// EstimateModel describes the model behind an estimate
+type EstimateModel struct {
+	Type          string `json:"type"`
+	TrainedAt     string `json:"trained_at"`
+	TrainingNames int    `json:"training_names"`
+}
+
+// EstimateResponse is a modelled, not observed, gender balance
+type EstimateResponse struct {
+	Name   string `json:"name"`
+	Source string `json:"source"` // always "modelled"
+	estimator.Estimate
+	Model EstimateModel `json:"model"`
+}
+
+// NameEstimate returns a modelled gender balance for any name, including
+// names with no recorded births
+func NameEstimate(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if cfg.Estimator == nil {
+			WriteError(w, http.StatusServiceUnavailable, "Gender estimator model is not loaded")
+			return
+		}
+
+		// Parse name (required)
+		name := strings.TrimSpace(r.URL.Query().Get("name"))
+		if name == "" {
+			WriteError(w, http.StatusBadRequest, "Invalid parameters: name parameter is required")
+			return
+		}
+
+		response := EstimateResponse{
+			Name:     name,
+			Source:   "modelled",
+			Estimate: cfg.Estimator.Estimate(name),
+			Model: EstimateModel{
+				Type:          estimator.ModelType,
+				TrainedAt:     cfg.Estimator.TrainedAt.Format("2006-01-02T15:04:05Z"),
+				TrainingNames: cfg.Estimator.Names,
+			},
+		}
+
+		// Return JSON response
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/supercakecrumb/nomia/internal/config"
+	"github.com/supercakecrumb/nomia/internal/db"
+	"github.com/supercakecrumb/nomia/internal/export"
+	"go.uber.org/zap"
+)
+
+// exportFlushRows is how many rows are written between flushes to the client
+const exportFlushRows = 500
+
+// NamesExport streams the full filtered names list as CSV, NDJSON or JSON.
+// It takes every /api/names filter; page, page_size and cursor are ignored.
+func NamesExport(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get year range for defaults
+		ctx := r.Context()
+		yearRange, err := cfg.DB.GetYearRange(ctx)
+		if err != nil {
+			WriteDatabaseError(w, err)
+			return
+		}
+
+		// Parse and validate parameters, leaving out pagination
+		query := r.URL.Query()
+		format := query.Get("format")
+		if format == "" {
+			format = export.FormatCSV
+		}
+		ew, err := export.NewWriter(format, w)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid parameters: %v", err))
+			return
+		}
+		query.Del("format")
+		query.Del("page")
+		query.Del("page_size")
+		query.Del("cursor")
+		params, err := db.ParseNamesListParams(query, yearRange.MinYear, yearRange.MaxYear)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid parameters: %v", err))
+			return
+		}
+
+		header, err := exportHeader(r, cfg, "names", params.FilterValues())
+		if err != nil {
+			WriteDatabaseError(w, err)
+			return
+		}
+
+		streamExport(w, cfg, ew, format, "names", header, export.NameColumns, func(row func([]interface{}) error) error {
+			return cfg.DB.StreamNamesList(ctx, params, func(nr db.NameRecord) error {
+				return row(export.NameRow(nr))
+			})
+		})
+	}
+}
+
+// exportHeader builds the reproducibility header from the applied filters
+// and the current data and dataset versions
+func exportHeader(r *http.Request, cfg *config.Config, name string, filters map[string][]string) (export.Header, error) {
+	version, err := cfg.DB.DataVersion(r.Context())
+	if err != nil {
+		return export.Header{}, err
+	}
+	datasets, err := cfg.DB.GetDatasetVersions(r.Context())
+	if err != nil {
+		return export.Header{}, err
+	}
+
+	header := export.Header{
+		Export:      name,
+		GeneratedAt: time.Now().UTC(),
+		DataVersion: version,
+		Filters:     make(map[string]string, len(filters)),
+		Datasets:    datasets,
+	}
+	for key, values := range filters {
+		header.Filters[key] = values[0]
+	}
+
+	return header, nil
+}
+
+// streamExport writes the header and the rows produced by fill through ew,
+// flushing to the client every exportFlushRows rows. Once the 200 status is
+// sent an error can no longer be reported in the response: it is logged and
+// the connection aborted, so the client sees a truncated download rather
+// than a complete file.
+func streamExport(w http.ResponseWriter, cfg *config.Config, ew export.Writer, format, filename string, header export.Header, columns []string, fill func(row func([]interface{}) error) error) {
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, format))
+	w.WriteHeader(http.StatusOK)
+
+	abort := func(err error) {
+		if cfg.Logger != nil {
+			cfg.Logger.Error("Export failed after the response started",
+				zap.String("export", filename),
+				zap.String("format", format),
+				zap.Error(err),
+			)
+		}
+		panic(http.ErrAbortHandler)
+	}
+
+	rc := http.NewResponseController(w)
+	if err := ew.Begin(header, columns); err != nil {
+		abort(err)
+	}
+
+	rows := 0
+	err := fill(func(values []interface{}) error {
+		if err := ew.Row(values); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushRows == 0 {
+			if err := ew.Flush(); err != nil {
+				return err
+			}
+			rc.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		abort(err)
+	}
+
+	if err := ew.End(); err != nil {
+		abort(err)
+	}
+}
+
+// NameTrendExport streams the per-country, per-year counts by sex of one or
+// more names, each row citing its source dataset
+func NameTrendExport(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get year range for defaults
+		ctx := r.Context()
+		yearRange, err := cfg.DB.GetYearRange(ctx)
+		if err != nil {
+			WriteDatabaseError(w, err)
+			return
+		}
+
+		// Parse and validate parameters
+		query := r.URL.Query()
+		format := query.Get("format")
+		if format == "" {
+			format = export.FormatCSV
+		}
+		ew, err := export.NewWriter(format, w)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid parameters: %v", err))
+			return
+		}
+		params, err := db.ParseTrendExportParams(query, yearRange.MinYear, yearRange.MaxYear)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid parameters: %v", err))
+			return
+		}
+
+		header, err := exportHeader(r, cfg, "name-trend", params.FilterValues())
+		if err != nil {
+			WriteDatabaseError(w, err)
+			return
+		}
+
+		streamExport(w, cfg, ew, format, "name-trend", header, export.TrendColumns, func(row func([]interface{}) error) error {
+			return cfg.DB.StreamTrendExport(ctx, params, func(tr db.TrendExportRow) error {
+				return row(export.TrendRow(tr))
+			})
+		})
+	}
+}
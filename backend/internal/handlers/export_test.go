@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/supercakecrumb/nomia/internal/db"
+)
+
+// failingStore serves one names-list row and then fails, like a query
+// that errors partway through an export
+type failingStore struct {
+	db.Store
+}
+
+func (s failingStore) StreamNamesList(ctx context.Context, params *db.NamesListParams, emit func(db.NameRecord) error) error {
+	if err := emit(db.NameRecord{Name: "Emma"}); err != nil {
+		return err
+	}
+	return errors.New("connection reset")
+}
+
+func TestNamesExportAbortsOnStreamError(t *testing.T) {
+	cfg := memoryConfig(t)
+	cfg.DB = failingStore{Store: cfg.DB}
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Fatalf("recover() = %v, want http.ErrAbortHandler", r)
+		}
+	}()
+
+	rec := httptest.NewRecorder()
+	NamesExport(cfg)(rec, httptest.NewRequest(http.MethodGet, "/api/names/export?format=csv", nil))
+	t.Fatalf("NamesExport() returned normally with status %d", rec.Code)
+}
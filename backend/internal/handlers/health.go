@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/supercakecrumb/nomia/internal/config"
+	"github.com/supercakecrumb/nomia/internal/db"
 )
 
 type HealthResponse struct {
@@ -13,15 +14,21 @@ type HealthResponse struct {
 	Timestamp time.Time `json:"timestamp"`
 	Version   string    `json:"version"`
 	Database  string    `json:"database"`
+
+	// Query cache hit/miss counters, omitted when caching is off
+	Cache *db.CacheStats `json:"cache,omitempty"`
 }
 
 func Health(cfg *config.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		dbStatus := "not_connected"
+		var cacheStats *db.CacheStats
 		if cfg.DB != nil {
+			cacheStats = cfg.DB.CacheStats()
+
 			// Test database connection
 			ctx := r.Context()
-			err := cfg.DB.Pool.Ping(ctx)
+			err := cfg.DB.Ping(ctx)
 			if err == nil {
 				dbStatus = "connected"
 			} else {
@@ -34,6 +41,7 @@ func Health(cfg *config.Config) http.HandlerFunc {
 			Timestamp: time.Now().UTC(),
 			Version:   "1.0.0",
 			Database:  dbStatus,
+			Cache:     cacheStats,
 		}
 
 		w.Header().Set("Content-Type", "application/json")
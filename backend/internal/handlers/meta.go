@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 
 	"github.com/supercakecrumb/nomia/internal/config"
@@ -11,22 +10,11 @@ import (
 // MetaYears returns the list of available years
 func MetaYears(cfg *config.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if cfg.FixtureMode {
-			// Load and return fixture JSON
-			data, err := LoadFixture("../spec-examples/meta-years.json")
-			if err != nil {
-				WriteError(w, http.StatusInternalServerError, err.Error())
-				return
-			}
-			WriteJSON(w, http.StatusOK, data)
-			return
-		}
-
 		// Query database
 		ctx := r.Context()
 		yearRange, err := cfg.DB.GetYearRange(ctx)
 		if err != nil {
-			WriteError(w, http.StatusInternalServerError, fmt.Sprintf("Database error: %v", err))
+			WriteDatabaseError(w, err)
 			return
 		}
 
@@ -39,22 +27,11 @@ func MetaYears(cfg *config.Config) http.HandlerFunc {
 // MetaCountries returns the list of available countries
 func MetaCountries(cfg *config.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if cfg.FixtureMode {
-			// Load and return fixture JSON
-			data, err := LoadFixture("../spec-examples/countries.json")
-			if err != nil {
-				WriteError(w, http.StatusInternalServerError, err.Error())
-				return
-			}
-			WriteJSON(w, http.StatusOK, data)
-			return
-		}
-
 		// Query database
 		ctx := r.Context()
 		countries, err := cfg.DB.GetCountries(ctx)
 		if err != nil {
-			WriteError(w, http.StatusInternalServerError, fmt.Sprintf("Database error: %v", err))
+			WriteDatabaseError(w, err)
 			return
 		}
 
@@ -12,22 +12,11 @@ import (
 // NamesList returns the list of names
 func NamesList(cfg *config.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if cfg.FixtureMode {
-			// Load and return fixture JSON
-			data, err := LoadFixture("../spec-examples/names-list.json")
-			if err != nil {
-				WriteError(w, http.StatusInternalServerError, err.Error())
-				return
-			}
-			WriteJSON(w, http.StatusOK, data)
-			return
-		}
-
 		// Get year range for defaults
 		ctx := r.Context()
 		yearRange, err := cfg.DB.GetYearRange(ctx)
 		if err != nil {
-			WriteError(w, http.StatusInternalServerError, fmt.Sprintf("Database error: %v", err))
+			WriteDatabaseError(w, err)
 			return
 		}
 
@@ -41,7 +30,7 @@ func NamesList(cfg *config.Config) http.HandlerFunc {
 		// Query database
 		response, err := cfg.DB.GetNamesList(ctx, params)
 		if err != nil {
-			WriteError(w, http.StatusInternalServerError, fmt.Sprintf("Database error: %v", err))
+			WriteDatabaseError(w, err)
 			return
 		}
 
@@ -54,22 +43,11 @@ func NamesList(cfg *config.Config) http.HandlerFunc {
 // NameTrend returns the trend details for a specific name
 func NameTrend(cfg *config.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if cfg.FixtureMode {
-			// Load and return fixture JSON
-			data, err := LoadFixture("../spec-examples/name-detail.json")
-			if err != nil {
-				WriteError(w, http.StatusInternalServerError, err.Error())
-				return
-			}
-			WriteJSON(w, http.StatusOK, data)
-			return
-		}
-
 		// Get year range for defaults
 		ctx := r.Context()
 		yearRange, err := cfg.DB.GetYearRange(ctx)
 		if err != nil {
-			WriteError(w, http.StatusInternalServerError, fmt.Sprintf("Database error: %v", err))
+			WriteDatabaseError(w, err)
 			return
 		}
 
@@ -82,16 +60,19 @@ func NameTrend(cfg *config.Config) http.HandlerFunc {
 
 		// Convert to db.NameTrendParams
 		dbParams := &db.NameTrendParams{
-			Name:      params.Name,
-			YearFrom:  params.YearFrom,
-			YearTo:    params.YearTo,
-			Countries: params.Countries,
+			Name:          params.Name,
+			YearFrom:      params.YearFrom,
+			YearTo:        params.YearTo,
+			Countries:     params.Countries,
+			Suppression:   params.Suppression,
+			Interval:      params.Interval,
+			ForecastYears: params.ForecastYears,
 		}
 
 		// Query database
 		response, err := cfg.DB.GetNameTrend(ctx, dbParams)
 		if err != nil {
-			WriteError(w, http.StatusInternalServerError, fmt.Sprintf("Database error: %v", err))
+			WriteDatabaseError(w, err)
 			return
 		}
 
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/supercakecrumb/nomia/internal/config"
+	"github.com/supercakecrumb/nomia/internal/db"
+	"github.com/supercakecrumb/nomia/internal/memstore"
+)
+
+// memoryConfig serves the handlers from an in-memory store of two SSA files
+func memoryConfig(t *testing.T) *config.Config {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"yob2000.txt": "Emma,F,900\nAlex,F,200\nNoah,M,800\nAlex,M,300\n",
+		"yob2001.txt": "Emma,F,1000\nAvery,F,150\nNoah,M,700\nAvery,M,50\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile() unexpected error = %v", err)
+		}
+	}
+
+	store := memstore.New(memstore.UnitedStates)
+	if _, err := store.LoadSSADir("US", dir); err != nil {
+		t.Fatalf("LoadSSADir() unexpected error = %v", err)
+	}
+	return &config.Config{DB: store}
+}
+
+func TestNamesList(t *testing.T) {
+	cfg := memoryConfig(t)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantNames  []string
+	}{
+		{"default", "", http.StatusOK, []string{"Emma", "Noah", "Alex", "Avery"}},
+		{"filtered", "?year_min=2001&name_glob=*a*", http.StatusOK, []string{"Emma", "Noah", "Avery"}},
+		{"invalid parameters", "?page_size=0", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NamesList(cfg)(rec, httptest.NewRequest(http.MethodGet, "/api/names"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantNames == nil {
+				return
+			}
+
+			var resp db.NamesListResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Unmarshal() unexpected error = %v", err)
+			}
+			if len(resp.Names) != len(tt.wantNames) {
+				t.Fatalf("got %d names, want %v", len(resp.Names), tt.wantNames)
+			}
+			for i, nr := range resp.Names {
+				if nr.Name != tt.wantNames[i] {
+					t.Errorf("names[%d] = %s, want %s", i, nr.Name, tt.wantNames[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUnsupportedEndpoint(t *testing.T) {
+	// Fixture mode serves the in-memory store, which leaves these out
+	cfg := memoryConfig(t)
+	cfg.FixtureMode = true
+	r := chi.NewRouter()
+	Routes(r, cfg)
+
+	targets := []string{
+		"/api/names/trending",
+		"/api/names/compare?names=Emma,Noah",
+		"/api/names/age-distribution?name=Emma",
+		"/api/names/Emma/similar-trajectory",
+	}
+
+	for _, target := range targets {
+		t.Run(target, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+			if rec.Code != http.StatusNotImplemented {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, http.StatusNotImplemented, rec.Body.String())
+			}
+		})
+	}
+}
@@ -8,17 +8,22 @@ import (
 )
 
 type NameTrendParams struct {
-	Name      string   // Required
-	YearFrom  int      // Optional, defaults to db_start
-	YearTo    int      // Optional, defaults to db_end
-	Countries []string // Optional, defaults to all countries
+	Name          string   // Required
+	YearFrom      int      // Optional, defaults to db_start
+	YearTo        int      // Optional, defaults to db_end
+	Countries     []string // Optional, defaults to all countries
+	Suppression   string   // Optional, "none" or "bounds", defaults to "none"
+	Interval      int      // Optional, years per time-series bucket, defaults to 1
+	ForecastYears int      // Optional, years to project (0-10), defaults to 0
 }
 
 func ParseNameTrendParams(query url.Values, dbStart, dbEnd int) (*NameTrendParams, error) {
 	params := &NameTrendParams{
-		YearFrom:  dbStart,
-		YearTo:    dbEnd,
-		Countries: []string{}, // empty = all countries
+		YearFrom:    dbStart,
+		YearTo:      dbEnd,
+		Countries:   []string{}, // empty = all countries
+		Suppression: "none",
+		Interval:    1,
 	}
 
 	// Parse name (required)
@@ -51,9 +56,48 @@ func ParseNameTrendParams(query url.Values, dbStart, dbEnd int) (*NameTrendParam
 		params.Countries = strings.Split(v, ",")
 	}
 
-	// Validate
+	// Parse suppression
+	if v := query.Get("suppression"); v != "" {
+		params.Suppression = v
+	}
+
+	// Parse interval
+	if v := query.Get("interval"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("interval must be an integer")
+		}
+		params.Interval = val
+	}
+
+	// Parse forecast_years
+	if v := query.Get("forecast_years"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("forecast_years must be an integer")
+		}
+		params.ForecastYears = val
+	}
+
+	// Validate; the series has a point per year, so the range must stay
+	// within the data
+	if params.YearFrom < dbStart || params.YearFrom > dbEnd {
+		return nil, fmt.Errorf("year_min must be between %d and %d", dbStart, dbEnd)
+	}
+	if params.YearTo < dbStart || params.YearTo > dbEnd {
+		return nil, fmt.Errorf("year_max must be between %d and %d", dbStart, dbEnd)
+	}
 	if params.YearFrom > params.YearTo {
-		return nil, fmt.Errorf("year_from must be <= year_to")
+		return nil, fmt.Errorf("year_min must be <= year_max")
+	}
+	if params.Suppression != "none" && params.Suppression != "bounds" {
+		return nil, fmt.Errorf("suppression must be either 'none' or 'bounds'")
+	}
+	if params.Interval < 1 || params.Interval > 50 {
+		return nil, fmt.Errorf("interval must be between 1 and 50")
+	}
+	if params.ForecastYears < 0 || params.ForecastYears > 10 {
+		return nil, fmt.Errorf("forecast_years must be between 0 and 10")
 	}
 
 	return params, nil
@@ -46,9 +46,9 @@ func TestParseNameTrendParams(t *testing.T) {
 		{
 			name: "custom year range",
 			query: url.Values{
-				"name":      []string{"Emma"},
-				"year_from": []string{"2022"},
-				"year_to":   []string{"2023"},
+				"name":     []string{"Emma"},
+				"year_min": []string{"2022"},
+				"year_max": []string{"2023"},
 			},
 			dbStart: 2020,
 			dbEnd:   2024,
@@ -68,14 +68,36 @@ func TestParseNameTrendParams(t *testing.T) {
 		{
 			name: "invalid year range",
 			query: url.Values{
-				"name":      []string{"Test"},
-				"year_from": []string{"2024"},
-				"year_to":   []string{"2022"},
+				"name":     []string{"Test"},
+				"year_min": []string{"2024"},
+				"year_max": []string{"2022"},
 			},
 			dbStart: 2020,
 			dbEnd:   2024,
 			wantErr: true,
-			errMsg:  "year_from must be <= year_to",
+			errMsg:  "year_min must be <= year_max",
+		},
+		{
+			name: "year_min before the data",
+			query: url.Values{
+				"name":     []string{"Alex"},
+				"year_min": []string{"0"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "year_min must be between 2020 and 2024",
+		},
+		{
+			name: "year_max past the data",
+			query: url.Values{
+				"name":     []string{"Alex"},
+				"year_max": []string{"5000000"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "year_max must be between 2020 and 2024",
 		},
 		{
 			name: "countries filter",
@@ -93,15 +115,93 @@ func TestParseNameTrendParams(t *testing.T) {
 			},
 		},
 		{
-			name: "invalid year_from",
+			name: "invalid year_min",
+			query: url.Values{
+				"name":     []string{"Test"},
+				"year_min": []string{"invalid"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "year_min must be an integer",
+		},
+		{
+			name: "suppression bounds",
+			query: url.Values{
+				"name":        []string{"Test"},
+				"suppression": []string{"bounds"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: false,
+			checkFunc: func(t *testing.T, p *NameTrendParams) {
+				if p.Suppression != "bounds" {
+					t.Errorf("Suppression = %s, want bounds", p.Suppression)
+				}
+			},
+		},
+		{
+			name: "invalid suppression",
+			query: url.Values{
+				"name":        []string{"Test"},
+				"suppression": []string{"impute"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "suppression must be either 'none' or 'bounds'",
+		},
+		{
+			name: "decade interval",
+			query: url.Values{
+				"name":     []string{"Test"},
+				"interval": []string{"10"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: false,
+			checkFunc: func(t *testing.T, p *NameTrendParams) {
+				if p.Interval != 10 {
+					t.Errorf("Interval = %d, want 10", p.Interval)
+				}
+			},
+		},
+		{
+			name: "invalid interval",
+			query: url.Values{
+				"name":     []string{"Test"},
+				"interval": []string{"0"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: true,
+			errMsg:  "interval must be between 1 and 50",
+		},
+		{
+			name: "forecast years",
+			query: url.Values{
+				"name":           []string{"Test"},
+				"forecast_years": []string{"5"},
+			},
+			dbStart: 2020,
+			dbEnd:   2024,
+			wantErr: false,
+			checkFunc: func(t *testing.T, p *NameTrendParams) {
+				if p.ForecastYears != 5 {
+					t.Errorf("ForecastYears = %d, want 5", p.ForecastYears)
+				}
+			},
+		},
+		{
+			name: "forecast years out of range",
 			query: url.Values{
-				"name":      []string{"Test"},
-				"year_from": []string{"invalid"},
+				"name":           []string{"Test"},
+				"forecast_years": []string{"25"},
 			},
 			dbStart: 2020,
 			dbEnd:   2024,
 			wantErr: true,
-			errMsg:  "year_from must be an integer",
+			errMsg:  "forecast_years must be between 0 and 10",
 		},
 	}
 
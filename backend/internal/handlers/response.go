@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
+
+	"github.com/supercakecrumb/nomia/internal/db"
 )
 
 // WriteJSON writes JSON data to the response with the specified status code
@@ -13,18 +15,19 @@ func WriteJSON(w http.ResponseWriter, status int, data []byte) {
 	w.Write(data)
 }
 
-// LoadFixture loads a JSON fixture file from the spec-examples directory
-func LoadFixture(path string) ([]byte, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load fixture %s: %w", path, err)
-	}
-	return data, nil
-}
-
 // WriteError writes an error response in JSON format
 func WriteError(w http.ResponseWriter, status int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	fmt.Fprintf(w, `{"error": "%s"}`, message)
 }
+
+// WriteDatabaseError writes a failed data store query: 501 when the store
+// cannot answer it (e.g. a snapshot lacking the tables), 500 otherwise
+func WriteDatabaseError(w http.ResponseWriter, err error) {
+	if errors.Is(err, db.ErrUnsupported) {
+		WriteError(w, http.StatusNotImplemented, fmt.Sprintf("Not available from this data source: %v", err))
+		return
+	}
+	WriteError(w, http.StatusInternalServerError, fmt.Sprintf("Database error: %v", err))
+}
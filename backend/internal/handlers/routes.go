@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/supercakecrumb/nomia/internal/config"
+	"github.com/supercakecrumb/nomia/internal/openapi"
+)
+
+// Routes registers every endpoint of the API. Each one must be described in
+// the OpenAPI spec served at openapi.Path.
+func Routes(r chi.Router, cfg *config.Config) {
+	r.Get(openapi.Path, openapi.Handler())
+	r.Get("/api/meta/years", MetaYears(cfg))
+	r.Get("/api/meta/countries", MetaCountries(cfg))
+	r.Get("/api/names", NamesList(cfg))
+	r.Get("/api/names/trend", NameTrend(cfg))
+	r.Get("/api/names/export", NamesExport(cfg))
+	r.Get("/api/names/trend/export", NameTrendExport(cfg))
+	r.Get("/api/names/trending", NamesTrending(cfg))
+	r.Get("/api/names/compare", NamesCompare(cfg))
+	r.Get("/api/names/estimate", NameEstimate(cfg))
+	r.Get("/api/names/age-distribution", NameAgeDistribution(cfg))
+	r.Get("/api/names/{name}/similar-trajectory", SimilarTrajectory(cfg))
+
+	// Health check endpoint
+	r.Get("/health", Health(cfg))
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/supercakecrumb/nomia/internal/middleware"
+	"github.com/supercakecrumb/nomia/internal/openapi"
+)
+
+func TestRoutesInSpec(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+
+	r := chi.NewRouter()
+	Routes(r, memoryConfig(t))
+
+	err = chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		item := doc.Paths.Find(route)
+		if item == nil || item.GetOperation(method) == nil {
+			t.Errorf("%s %s is not described in the OpenAPI spec", method, route)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() unexpected error = %v", err)
+	}
+}
+
+// Responses served from the in-memory store must match the spec; the
+// validator turns any mismatch into a 500
+func TestRoutesConformToSpec(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+	validator, err := openapi.Validator(doc)
+	if err != nil {
+		t.Fatalf("Validator() unexpected error = %v", err)
+	}
+
+	// Same order as the server, so 304 responses are validated too
+	cfg := memoryConfig(t)
+	r := chi.NewRouter()
+	r.Use(validator)
+	r.Use(middleware.ConditionalGET(DataVersion(cfg), []middleware.CacheRule{{Prefix: "/api/", CacheControl: "no-cache"}}))
+	Routes(r, cfg)
+
+	tests := []struct {
+		target     string
+		wantStatus int
+	}{
+		{openapi.Path, http.StatusOK},
+		{"/api/meta/years", http.StatusOK},
+		{"/api/meta/countries", http.StatusOK},
+		{"/api/names", http.StatusOK},
+		{"/api/names?year_min=2001&name_glob=a*&sort_key=name&normalize=per_births", http.StatusOK},
+		{"/api/names?balance_year_min=2000&balance_year_max=2000&balance_scope=every_country", http.StatusOK},
+		{"/api/names?countries=SE", http.StatusOK},
+		{"/api/names?page_size=5", http.StatusBadRequest},
+		{"/api/names?year_min=2001&year_max=2000", http.StatusBadRequest},
+		{"/api/names/trend?name=Alex", http.StatusOK},
+		{"/api/names/trend?name=Alex&interval=2&suppression=bounds", http.StatusOK},
+		{"/api/names/trend?name=Nobody", http.StatusNotFound},
+		{"/api/names/trend", http.StatusBadRequest},
+		{"/api/names/export?format=json", http.StatusOK},
+		{"/api/names/export?format=csv", http.StatusOK},
+		{"/api/names/export?format=pdf", http.StatusBadRequest},
+		{"/api/names/trend/export?names=Emma,Noah&format=json", http.StatusOK},
+		{"/api/names/trend/export?names=Emma&format=xlsx", http.StatusOK},
+		{"/api/names/trending", http.StatusNotImplemented},
+		{"/api/names/compare?names=Emma,Noah", http.StatusNotImplemented},
+		{"/api/names/age-distribution?name=Emma", http.StatusNotImplemented},
+		{"/api/names/Emma/similar-trajectory", http.StatusNotImplemented},
+		{"/api/names/estimate?name=Emma", http.StatusServiceUnavailable},
+		{"/health", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if strings.Contains(rec.Body.String(), "does not match the API contract") {
+				t.Errorf("response does not match the spec: %s", rec.Body.String())
+			}
+		})
+	}
+
+	t.Run("not modified", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/names", nil))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/names", nil)
+		req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("status = %d, want %d (%s)", rec.Code, http.StatusNotModified, rec.Body.String())
+		}
+	})
+}
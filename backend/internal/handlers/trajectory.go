@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/supercakecrumb/nomia/internal/config"
+	"github.com/supercakecrumb/nomia/internal/db"
+)
+
+// SimilarTrajectory returns names whose popularity shape and gender balance
+// path over the decades resemble the given name
+func SimilarTrajectory(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse and validate parameters
+		ctx := r.Context()
+		params, err := db.ParseSimilarTrajectoryParams(chi.URLParam(r, "name"), r.URL.Query())
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid parameters: %v", err))
+			return
+		}
+
+		// Query database
+		response, err := cfg.DB.GetSimilarTrajectories(ctx, params)
+		if errors.Is(err, db.ErrNameNotFound) {
+			WriteError(w, http.StatusNotFound, fmt.Sprintf("Name '%s' not found", params.Name))
+			return
+		}
+		if err != nil {
+			WriteDatabaseError(w, err)
+			return
+		}
+
+		// Return JSON response
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/supercakecrumb/nomia/internal/config"
+	"github.com/supercakecrumb/nomia/internal/db"
+)
+
+// NamesTrending returns the biggest risers, fallers, newcomers and
+// disappearances between a recent and a baseline year window
+func NamesTrending(cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get year range for defaults
+		ctx := r.Context()
+		yearRange, err := cfg.DB.GetYearRange(ctx)
+		if err != nil {
+			WriteDatabaseError(w, err)
+			return
+		}
+
+		// Parse and validate parameters
+		params, err := db.ParseTrendingParams(r.URL.Query(), yearRange.MinYear, yearRange.MaxYear)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid parameters: %v", err))
+			return
+		}
+
+		// Query database
+		response, err := cfg.DB.GetTrendingNames(ctx, params)
+		if err != nil {
+			WriteDatabaseError(w, err)
+			return
+		}
+
+		// Return JSON response
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
@@ -0,0 +1,88 @@
+// Package lifetable reads actuarial period life tables used to estimate how
+// many people born in a given year are still alive.
+package lifetable
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Radix is the number of births a life table's survivor column starts from
+const Radix = 100000
+
+// FormatSSAPeriod identifies the SSA period life table format
+const FormatSSAPeriod = "SSA-PERIOD"
+
+// Table holds survivors to each exact age (l_x), indexed by age, out of
+// Radix births
+type Table struct {
+	Female []float64
+	Male   []float64
+}
+
+// ParseSSAPeriod parses a plain-text copy of the SSA period life table
+// (Actuarial Life Table, table 4c6). Each data row has seven columns:
+//
+//	exact age, male death probability, male number of lives, male life
+//	expectancy, female death probability, female number of lives, female
+//	life expectancy
+//
+// Columns may be separated by whitespace, tabs or commas. Thousands
+// separators inside quoted numbers are not supported, so "100,000" must be
+// written as 100000. Lines that do not start with a number (titles, column
+// headers) are skipped. Ages must start at 0 and be consecutive.
+func ParseSSAPeriod(r io.Reader) (*Table, error) {
+	table := &Table{}
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		fields := strings.FieldsFunc(scanner.Text(), func(c rune) bool {
+			return c == ' ' || c == '\t' || c == ','
+		})
+		if len(fields) == 0 {
+			continue
+		}
+
+		// Skip headers
+		age, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("invalid format at line %d: expected 7 fields, got %d", lineNum, len(fields))
+		}
+		if age != len(table.Male) {
+			return nil, fmt.Errorf("invalid age at line %d: got %d, want %d", lineNum, age, len(table.Male))
+		}
+
+		male, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid male number of lives at line %d: %v", lineNum, err)
+		}
+		female, err := strconv.ParseFloat(fields[5], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid female number of lives at line %d: %v", lineNum, err)
+		}
+		if male < 0 || male > Radix || female < 0 || female > Radix {
+			return nil, fmt.Errorf("invalid number of lives at line %d: must be between 0 and %d", lineNum, Radix)
+		}
+
+		table.Male = append(table.Male, male)
+		table.Female = append(table.Female, female)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(table.Male) == 0 {
+		return nil, fmt.Errorf("no life table rows found")
+	}
+
+	return table, nil
+}
@@ -0,0 +1,68 @@
+package lifetable
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSSAPeriod(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantErr  string
+		wantAges int
+	}{
+		{
+			name: "whitespace table with headers",
+			input: `Period Life Table, 2021
+Exact  Male                      Female
+age    Death prob  Lives  Exp.   Death prob  Lives  Exp.
+0      0.005837   100000  73.37  0.004907   100000  79.26
+1      0.000410    99416  72.80  0.000316    99509  78.65
+2      0.000254    99376  71.83  0.000196    99478  77.68
+`,
+			wantAges: 3,
+		},
+		{
+			name:     "comma separated",
+			input:    "0,0.005837,100000,73.37,0.004907,100000,79.26\n1,0.000410,99416,72.80,0.000316,99509,78.65\n",
+			wantAges: 2,
+		},
+		{
+			name:    "wrong column count",
+			input:   "0 0.005837 100000 73.37\n",
+			wantErr: "expected 7 fields",
+		},
+		{
+			name:    "ages not consecutive",
+			input:   "0 0.1 100000 70 0.1 100000 75\n2 0.1 90000 69 0.1 90000 74\n",
+			wantErr: "invalid age at line 2",
+		},
+		{
+			name:    "empty file",
+			input:   "Period Life Table\n",
+			wantErr: "no life table rows found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := ParseSSAPeriod(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseSSAPeriod() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSSAPeriod() unexpected error = %v", err)
+			}
+			if len(table.Male) != tt.wantAges || len(table.Female) != tt.wantAges {
+				t.Errorf("ages = %d/%d, want %d", len(table.Male), len(table.Female), tt.wantAges)
+			}
+			if table.Male[0] != Radix || table.Female[0] != Radix {
+				t.Errorf("age 0 lives = %v/%v, want %d", table.Male[0], table.Female[0], Radix)
+			}
+		})
+	}
+}
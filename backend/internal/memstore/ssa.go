@@ -0,0 +1,74 @@
+package memstore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/supercakecrumb/nomia/internal/db"
+	"github.com/supercakecrumb/nomia/internal/ssa"
+)
+
+// LoadSSADir loads every yobYYYY.txt file in dir for the country, in year
+// order, and returns how many files were loaded
+func (s *Store) LoadSSADir(countryCode, dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && ssa.IsFileName(e.Name()) {
+			files = append(files, e.Name())
+		}
+	}
+	sort.Strings(files)
+
+	for _, f := range files {
+		if err := s.LoadSSAFile(countryCode, filepath.Join(dir, f)); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(files), nil
+}
+
+// LoadSSAFile loads one SSA-format file (name,gender,count per line), taking
+// the year from its yobYYYY.txt name
+func (s *Store) LoadSSAFile(countryCode, path string) error {
+	fileName := filepath.Base(path)
+	year, err := ssa.FileYear(fileName)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	records, err := ssa.ParseFile(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	return s.Add(countryCode, year, fileName, ssa.SuppressionThreshold, ssaCounts(records, countryCode, year))
+}
+
+// ssaCounts folds the records of an SSA file into one count per name with
+// both sexes
+func ssaCounts(records []ssa.Record, countryCode string, year int) []db.NameCount {
+	var counts []db.NameCount
+	index := make(map[string]int)
+	for _, r := range records {
+		i, ok := index[r.Name]
+		if !ok {
+			i = len(counts)
+			index[r.Name] = i
+			counts = append(counts, db.NameCount{Name: r.Name, CountryCode: countryCode, Year: year})
+		}
+		if r.Gender == "F" {
+			counts[i].FemaleCount += r.Count
+		} else {
+			counts[i].MaleCount += r.Count
+		}
+	}
+	return counts
+}
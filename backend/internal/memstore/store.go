@@ -0,0 +1,326 @@
+// Package memstore serves the read API from name counts held in memory, for
+// tests and frontend development without PostgreSQL.
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/supercakecrumb/nomia/internal/db"
+)
+
+// UnitedStates is the SSA country as the migrations seed it
+var UnitedStates = db.Country{
+	Code:                             "US",
+	Name:                             "United States",
+	DataSourceName:                   "Social Security Administration",
+	DataSourceURL:                    "https://www.ssa.gov/oact/babynames/",
+	DataSourceDescription:            "Annual baby name data from SSA records since 1880. Includes all names with at least 5 occurrences.",
+	DataSourceRequiresManualDownload: true,
+}
+
+// Store holds name counts in memory and answers the read API by running the
+// same pipelines as the SQL queries in Go. Trending names, comparisons, age
+// distributions and similar trajectories need tables it does not hold and
+// return db.ErrUnsupported, and trend points carry no rank.
+type Store struct {
+	mu sync.RWMutex
+
+	countries   map[string]db.Country
+	datasets    []dataset
+	counts      []count
+	births      []db.CountryYearBirths
+	yearRange   db.YearRange
+	dataVersion int64
+}
+
+// dataset is one loaded file
+type dataset struct {
+	id                   int
+	countryCode          string
+	fileName             string
+	year                 int
+	suppressionThreshold int
+	loadedAt             time.Time
+}
+
+// count is a name count with the dataset it was read from
+type count struct {
+	db.NameCount
+	datasetID int
+}
+
+var _ db.Store = (*Store)(nil)
+
+// New returns an empty store that accepts data for the given countries
+func New(countries ...db.Country) *Store {
+	s := &Store{countries: make(map[string]db.Country)}
+	for _, c := range countries {
+		s.countries[c.Code] = c
+	}
+	return s
+}
+
+// Add loads the counts of one dataset covering a single year. Every count
+// must belong to the country and year; names below suppressionThreshold are
+// treated as omitted from the dataset.
+func (s *Store) Add(countryCode string, year int, fileName string, suppressionThreshold int, counts []db.NameCount) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.countries[countryCode]; !ok {
+		return fmt.Errorf("country %s not found in store", countryCode)
+	}
+	for _, c := range counts {
+		if c.CountryCode != countryCode || c.Year != year {
+			return fmt.Errorf("%s: count of %s is for %s %d, not %s %d", fileName, c.Name, c.CountryCode, c.Year, countryCode, year)
+		}
+	}
+
+	d := dataset{
+		id:                   len(s.datasets) + 1,
+		countryCode:          countryCode,
+		fileName:             fileName,
+		year:                 year,
+		suppressionThreshold: suppressionThreshold,
+		loadedAt:             time.Now().UTC(),
+	}
+	s.datasets = append(s.datasets, d)
+
+	var total int64
+	for _, c := range counts {
+		s.counts = append(s.counts, count{NameCount: c, datasetID: d.id})
+		total += int64(c.TotalCount())
+	}
+
+	// Births are the sum of the name counts, as the import tool records them
+	found := false
+	for i := range s.births {
+		if s.births[i].CountryCode == countryCode && s.births[i].Year == year {
+			s.births[i].TotalBirths += total
+			found = true
+		}
+	}
+	if !found {
+		s.births = append(s.births, db.CountryYearBirths{CountryCode: countryCode, Year: year, TotalBirths: total})
+	}
+
+	if len(s.datasets) == 1 || year < s.yearRange.MinYear {
+		s.yearRange.MinYear = year
+	}
+	if len(s.datasets) == 1 || year > s.yearRange.MaxYear {
+		s.yearRange.MaxYear = year
+	}
+	s.dataVersion++
+
+	return nil
+}
+
+// snapshot returns the loaded data; the slices are only ever appended to,
+// so callers may read them without holding the lock
+func (s *Store) snapshot() ([]count, []db.CountryYearBirths, db.YearRange) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.counts, append([]db.CountryYearBirths(nil), s.births...), s.yearRange
+}
+
+// nameCounts returns the loaded counts without their datasets
+func nameCounts(counts []count) []db.NameCount {
+	out := make([]db.NameCount, len(counts))
+	for i, c := range counts {
+		out[i] = c.NameCount
+	}
+	return out
+}
+
+// Ping always succeeds: the data is in memory
+func (s *Store) Ping(ctx context.Context) error {
+	return nil
+}
+
+// CacheStats returns nil: results are computed on every request
+func (s *Store) CacheStats() *db.CacheStats {
+	return nil
+}
+
+// DataVersion returns the number of datasets loaded so far
+func (s *Store) DataVersion(ctx context.Context) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dataVersion, nil
+}
+
+func (s *Store) GetYearRange(ctx context.Context) (*db.YearRange, error) {
+	_, _, yearRange := s.snapshot()
+	return &yearRange, nil
+}
+
+func (s *Store) GetCountries(ctx context.Context) (*db.CountriesResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	countries := make([]db.Country, 0, len(s.countries))
+	for _, c := range s.countries {
+		countries = append(countries, c)
+	}
+	sort.Slice(countries, func(i, j int) bool { return countries[i].Name < countries[j].Name })
+
+	return &db.CountriesResponse{Countries: countries}, nil
+}
+
+func (s *Store) GetDatasetVersions(ctx context.Context) ([]db.DatasetVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byCountry := make(map[string]*db.DatasetVersion)
+	for _, d := range s.datasets {
+		v := byCountry[d.countryCode]
+		if v == nil {
+			v = &db.DatasetVersion{Country: d.countryCode, YearFrom: d.year, YearTo: d.year}
+			byCountry[d.countryCode] = v
+		}
+		v.Datasets++
+		v.YearFrom = min(v.YearFrom, d.year)
+		v.YearTo = max(v.YearTo, d.year)
+		if d.loadedAt.After(v.LastParsedAt) {
+			v.LastParsedAt = d.loadedAt
+		}
+	}
+
+	versions := []db.DatasetVersion{}
+	for _, v := range byCountry {
+		versions = append(versions, *v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Country < versions[j].Country })
+
+	return versions, nil
+}
+
+func (s *Store) GetNamesList(ctx context.Context, params *db.NamesListParams) (*db.NamesListResponse, error) {
+	counts, births, yearRange := s.snapshot()
+	return db.EvaluateNamesList(nameCounts(counts), births, params, &yearRange), nil
+}
+
+func (s *Store) StreamNamesList(ctx context.Context, params *db.NamesListParams, emit func(db.NameRecord) error) error {
+	counts, births, _ := s.snapshot()
+	for _, nr := range db.EvaluateNamesListAll(nameCounts(counts), births, params) {
+		if err := emit(nr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetNameTrend(ctx context.Context, params *db.NameTrendParams) (*db.NameTrendResponse, error) {
+	counts, births, yearRange := s.snapshot()
+
+	s.mu.RLock()
+	datasets := make([]db.DatasetCoverage, len(s.datasets))
+	for i, d := range s.datasets {
+		datasets[i] = db.DatasetCoverage{
+			CountryCode:          d.countryCode,
+			YearFrom:             d.year,
+			YearTo:               d.year,
+			SuppressionThreshold: d.suppressionThreshold,
+		}
+	}
+	countryNames := make(map[string]string, len(s.countries))
+	for code, c := range s.countries {
+		countryNames[code] = c.Name
+	}
+	s.mu.RUnlock()
+
+	return db.EvaluateNameTrend(nameCounts(counts), datasets, births, countryNames, params, &yearRange), nil
+}
+
+func (s *Store) StreamTrendExport(ctx context.Context, params *db.TrendExportParams, emit func(db.TrendExportRow) error) error {
+	counts, _, _ := s.snapshot()
+
+	s.mu.RLock()
+	datasets := append([]dataset(nil), s.datasets...)
+	countries := make(map[string]db.Country, len(s.countries))
+	for code, c := range s.countries {
+		countries[code] = c
+	}
+	s.mu.RUnlock()
+
+	matchName := db.MatchNames(params.Names)
+	var rows []db.TrendExportRow
+	for _, c := range counts {
+		if c.Year < params.YearFrom || c.Year > params.YearTo || !matchName(c.Name) {
+			continue
+		}
+		if len(params.Countries) > 0 && !contains(params.Countries, c.CountryCode) {
+			continue
+		}
+		d := datasets[c.datasetID-1]
+		rows = append(rows, db.TrendExportRow{
+			Name:           c.Name,
+			CountryCode:    c.CountryCode,
+			Year:           c.Year,
+			TotalCount:     c.TotalCount(),
+			FemaleCount:    c.FemaleCount,
+			MaleCount:      c.MaleCount,
+			UnknownCount:   c.UnknownCount,
+			DatasetID:      d.id,
+			SourceName:     countries[c.CountryCode].DataSourceName,
+			SourceURL:      countries[c.CountryCode].DataSourceURL,
+			SourceFileName: d.fileName,
+		})
+	}
+
+	// Same order as the SQL export: by name case-insensitively, country,
+	// year and dataset
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if la, lb := strings.ToLower(a.Name), strings.ToLower(b.Name); la != lb {
+			return la < lb
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.CountryCode != b.CountryCode {
+			return a.CountryCode < b.CountryCode
+		}
+		if a.Year != b.Year {
+			return a.Year < b.Year
+		}
+		return a.DatasetID < b.DatasetID
+	})
+
+	for _, r := range rows {
+		if err := emit(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetTrendingNames(ctx context.Context, params *db.TrendingParams) (*db.TrendingResponse, error) {
+	return nil, db.ErrUnsupported
+}
+
+func (s *Store) GetNamesCompare(ctx context.Context, params *db.CompareParams) (*db.CompareResponse, error) {
+	return nil, db.ErrUnsupported
+}
+
+func (s *Store) GetAgeDistribution(ctx context.Context, params *db.AgeDistributionParams) (*db.AgeDistributionResponse, error) {
+	return nil, db.ErrUnsupported
+}
+
+func (s *Store) GetSimilarTrajectories(ctx context.Context, params *db.SimilarTrajectoryParams) (*db.SimilarTrajectoryResponse, error) {
+	return nil, db.ErrUnsupported
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package memstore
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/supercakecrumb/nomia/internal/db"
+	"github.com/supercakecrumb/nomia/internal/ssa"
+)
+
+// loadTestStore writes two SSA files and loads them into a new store
+func loadTestStore(t *testing.T) *Store {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"yob2000.txt": "Emma,F,900\nAlex,F,200\nNoah,M,800\nAlex,M,300\n",
+		"yob2001.txt": "Emma,F,1000\nAvery,F,150\nEmma,M,10\nNoah,M,700\nAvery,M,50\n",
+		"notes.txt":   "not a data file",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile() unexpected error = %v", err)
+		}
+	}
+
+	s := New(UnitedStates)
+	n, err := s.LoadSSADir("US", dir)
+	if err != nil {
+		t.Fatalf("LoadSSADir() unexpected error = %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("LoadSSADir() loaded %d files, want 2", n)
+	}
+	return s
+}
+
+func TestLoadSSADir(t *testing.T) {
+	s := loadTestStore(t)
+	ctx := context.Background()
+
+	if yr, _ := s.GetYearRange(ctx); *yr != (db.YearRange{MinYear: 2000, MaxYear: 2001}) {
+		t.Errorf("GetYearRange() = %+v, want 2000-2001", yr)
+	}
+	if v, _ := s.DataVersion(ctx); v != 2 {
+		t.Errorf("DataVersion() = %d, want 2", v)
+	}
+
+	versions, _ := s.GetDatasetVersions(ctx)
+	if len(versions) != 1 || versions[0].Datasets != 2 || versions[0].YearFrom != 2000 || versions[0].YearTo != 2001 {
+		t.Errorf("GetDatasetVersions() = %+v, want 2 US datasets over 2000-2001", versions)
+	}
+
+	want := []db.CountryYearBirths{
+		{CountryCode: "US", Year: 2000, TotalBirths: 2200},
+		{CountryCode: "US", Year: 2001, TotalBirths: 1910},
+	}
+	if !reflect.DeepEqual(s.births, want) {
+		t.Errorf("births = %+v, want %+v", s.births, want)
+	}
+}
+
+func TestLoadSSAFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		country string
+	}{
+		{"bad file name", "names2000.txt", "Emma,F,5\n", "US"},
+		{"bad gender", "yob2000.txt", "Emma,X,5\n", "US"},
+		{"bad count", "yob2000.txt", "Emma,F,0\n", "US"},
+		{"missing field", "yob2000.txt", "Emma,F\n", "US"},
+		{"unknown country", "yob2000.txt", "Emma,F,5\n", "SE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("WriteFile() unexpected error = %v", err)
+			}
+			if err := New(UnitedStates).LoadSSAFile(tt.country, path); err == nil {
+				t.Errorf("LoadSSAFile() expected error")
+			}
+		})
+	}
+}
+
+func TestStoreNamesList(t *testing.T) {
+	s := loadTestStore(t)
+
+	tests := []struct {
+		query url.Values
+		want  []string
+	}{
+		{url.Values{}, []string{"Emma", "Noah", "Alex", "Avery"}},
+		{url.Values{"year_min": {"2001"}}, []string{"Emma", "Noah", "Avery"}},
+		{url.Values{"name_glob": {"a*"}}, []string{"Alex", "Avery"}},
+		{url.Values{"gender_balance_min": {"50"}}, []string{"Noah", "Alex"}},
+		{url.Values{"sort_key": {"name"}}, []string{"Alex", "Avery", "Emma", "Noah"}},
+		{url.Values{"countries": {"SE"}}, []string{}},
+	}
+
+	for _, tt := range tests {
+		params, err := db.ParseNamesListParams(tt.query, 2000, 2001)
+		if err != nil {
+			t.Fatalf("ParseNamesListParams(%v) unexpected error = %v", tt.query, err)
+		}
+		resp, err := s.GetNamesList(context.Background(), params)
+		if err != nil {
+			t.Fatalf("GetNamesList(%v) unexpected error = %v", tt.query, err)
+		}
+		got := []string{}
+		for _, nr := range resp.Names {
+			got = append(got, nr.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetNamesList(%v) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestStoreNameTrend(t *testing.T) {
+	s := loadTestStore(t)
+
+	params := &db.NameTrendParams{Name: "alex", YearFrom: 2000, YearTo: 2001, Interval: 1, Suppression: "none"}
+	resp, err := s.GetNameTrend(context.Background(), params)
+	if err != nil {
+		t.Fatalf("GetNameTrend() unexpected error = %v", err)
+	}
+
+	if resp.Summary.TotalCount != 500 || resp.Summary.GenderBalance != 60 {
+		t.Errorf("summary = %+v, want 500 births at balance 60", resp.Summary)
+	}
+
+	// Alex is missing from the 2001 file, so both sexes were below the threshold
+	p := resp.TimeSeries[1]
+	if p.Year != 2001 || !p.Censored || p.CensoredFemaleMax != ssa.SuppressionThreshold-1 {
+		t.Errorf("2001 point = %+v, want censored below %d", p, ssa.SuppressionThreshold)
+	}
+}
+
+func TestStoreTrendExport(t *testing.T) {
+	s := loadTestStore(t)
+
+	params := &db.TrendExportParams{Names: []string{"noah", "EMMA"}, YearFrom: 2000, YearTo: 2001}
+	var got []string
+	err := s.StreamTrendExport(context.Background(), params, func(r db.TrendExportRow) error {
+		got = append(got, r.Citation())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamTrendExport() unexpected error = %v", err)
+	}
+
+	want := []string{
+		"Social Security Administration (US), 2000, file yob2000.txt, https://www.ssa.gov/oact/babynames/",
+		"Social Security Administration (US), 2001, file yob2001.txt, https://www.ssa.gov/oact/babynames/",
+		"Social Security Administration (US), 2000, file yob2000.txt, https://www.ssa.gov/oact/babynames/",
+		"Social Security Administration (US), 2001, file yob2001.txt, https://www.ssa.gov/oact/babynames/",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("StreamTrendExport() citations = %v, want %v", got, want)
+	}
+
+	// Names are matched exactly, so LIKE wildcards match nothing
+	params.Names = []string{"Em%", "_oah"}
+	err = s.StreamTrendExport(context.Background(), params, func(r db.TrendExportRow) error {
+		t.Errorf("StreamTrendExport() emitted %+v for wildcard names, want no rows", r)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamTrendExport() unexpected error = %v", err)
+	}
+}
+
+func TestStoreUnsupported(t *testing.T) {
+	s := New()
+	if _, err := s.GetNamesCompare(context.Background(), &db.CompareParams{}); !errors.Is(err, db.ErrUnsupported) {
+		t.Errorf("GetNamesCompare() error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestLoadBundledExamples(t *testing.T) {
+	s := New(UnitedStates)
+	if _, err := s.LoadSSADir("US", "../../../names-example"); err != nil {
+		t.Fatalf("LoadSSADir() unexpected error = %v", err)
+	}
+
+	params, err := db.ParseNamesListParams(url.Values{"name_glob": {"oliv*"}, "gender_balance_max": {"10"}}, 2023, 2024)
+	if err != nil {
+		t.Fatalf("ParseNamesListParams() unexpected error = %v", err)
+	}
+	resp, err := s.GetNamesList(context.Background(), params)
+	if err != nil {
+		t.Fatalf("GetNamesList() unexpected error = %v", err)
+	}
+
+	if resp.Meta.DbStart != 2023 || resp.Meta.DbEnd != 2024 {
+		t.Errorf("meta years = %d-%d, want 2023-2024", resp.Meta.DbStart, resp.Meta.DbEnd)
+	}
+	if len(resp.Names) == 0 || resp.Names[0].Name != "Olivia" {
+		t.Fatalf("first name = %+v, want Olivia", resp.Names)
+	}
+	for _, nr := range resp.Names {
+		if nr.GenderBalance > 10 {
+			t.Errorf("%s gender balance = %v, want <= 10", nr.Name, nr.GenderBalance)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// CacheRule sets the Cache-Control header for paths under Prefix
+type CacheRule struct {
+	Prefix       string
+	CacheControl string
+}
+
+// cacheWriter drops the caching headers when the handler fails so error
+// responses are never stored
+type cacheWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (cw *cacheWriter) WriteHeader(code int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		if code != http.StatusOK {
+			cw.Header().Del("ETag")
+			cw.Header().Set("Cache-Control", "no-store")
+		}
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *cacheWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (cw *cacheWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// ConditionalGET tags GET responses of paths matching a rule with an ETag
+// derived from the data version and the normalized request, answers
+// matching If-None-Match requests with 304 without running the handler, and
+// sets the rule's Cache-Control. The first matching rule wins; other paths
+// and requests whose version cannot be read pass through untouched.
+func ConditionalGET(version func(ctx context.Context) (string, error), rules []CacheRule) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rule, ok := matchCacheRule(r.URL.Path, rules)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			v, err := version(r.Context())
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			etag := ETag(v, r)
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Cache-Control", rule.CacheControl)
+
+			if etagMatches(r.Header.Get("If-None-Match"), etag) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			next.ServeHTTP(&cacheWriter{ResponseWriter: w}, r)
+		})
+	}
+}
+
+// ETag returns a weak entity tag for the request under the data version.
+// The query is normalized by sorting its keys (url.Values.Encode), so
+// parameter order does not change the tag.
+func ETag(version string, r *http.Request) string {
+	sum := sha256.Sum256([]byte(version + "\n" + r.URL.Path + "\n" + r.URL.Query().Encode()))
+	return `W/"` + hex.EncodeToString(sum[:12]) + `"`
+}
+
+func matchCacheRule(path string, rules []CacheRule) (CacheRule, bool) {
+	for _, rule := range rules {
+		if strings.HasPrefix(path, rule.Prefix) {
+			return rule, true
+		}
+	}
+	return CacheRule{}, false
+}
+
+// etagMatches implements the weak comparison of If-None-Match
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConditionalGET(t *testing.T) {
+	version := "1"
+	versionErr := error(nil)
+	calls := 0
+	status := http.StatusOK
+
+	handler := ConditionalGET(
+		func(ctx context.Context) (string, error) { return version, versionErr },
+		[]CacheRule{
+			{Prefix: "/api/meta/", CacheControl: "public, max-age=86400"},
+			{Prefix: "/api/", CacheControl: "public, max-age=300"},
+		},
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+		w.Write([]byte("{}"))
+	}))
+
+	get := func(target, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := get("/api/names?top_n=10&countries=US", "")
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("no ETag on first response")
+	}
+	if cc := first.Header().Get("Cache-Control"); cc != "public, max-age=300" {
+		t.Errorf("Cache-Control = %q, want names rule", cc)
+	}
+
+	// Parameter order does not change the tag
+	reordered := get("/api/names?countries=US&top_n=10", "")
+	if reordered.Header().Get("ETag") != etag {
+		t.Errorf("ETag changed with parameter order")
+	}
+
+	notModified := get("/api/names?top_n=10&countries=US", `"other", `+etag)
+	if notModified.Code != http.StatusNotModified {
+		t.Errorf("status = %d, want 304", notModified.Code)
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2 (not for the 304)", calls)
+	}
+
+	if get("/api/names?top_n=20&countries=US", etag).Code != http.StatusOK {
+		t.Errorf("different query matched the ETag")
+	}
+
+	version = "2"
+	if get("/api/names?top_n=10&countries=US", etag).Code != http.StatusOK {
+		t.Errorf("ETag matched after the data version changed")
+	}
+
+	if cc := get("/api/meta/years", "").Header().Get("Cache-Control"); cc != "public, max-age=86400" {
+		t.Errorf("meta Cache-Control = %q, want long max-age", cc)
+	}
+
+	if rec := get("/health", ""); rec.Header().Get("ETag") != "" || rec.Header().Get("Cache-Control") != "" {
+		t.Errorf("unmatched path got caching headers")
+	}
+
+	status = http.StatusBadRequest
+	failed := get("/api/names?top_n=oops", "")
+	if failed.Header().Get("ETag") != "" || failed.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("error response kept caching headers: %v", failed.Header())
+	}
+
+	status = http.StatusOK
+	versionErr = errors.New("db down")
+	if rec := get("/api/names", ""); rec.Header().Get("ETag") != "" {
+		t.Errorf("ETag set without a data version")
+	}
+}
@@ -0,0 +1,169 @@
+// Package openapi embeds the OpenAPI 3 specification of the HTTP API and
+// validates requests and responses against it.
+package openapi
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// Path is where the server serves the specification
+const Path = "/api/openapi.yaml"
+
+// Spec is the OpenAPI document, the source of truth for the API contract
+//
+//go:embed openapi.yaml
+var Spec []byte
+
+// Load parses and validates the embedded specification
+func Load() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(Spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+	return doc, nil
+}
+
+// Handler serves the embedded specification
+func Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.WriteHeader(http.StatusOK)
+		w.Write(Spec)
+	}
+}
+
+// Validator checks every request to a route of the spec against it,
+// answering 400 without running the handler when the request does not
+// conform, and replaces responses that do not match their declared status
+// and schema with a 500. Responses are buffered to be checked, so this is
+// meant for tests and development rather than production. Requests to
+// paths missing from the spec pass through untouched.
+func Validator(doc *openapi3.T) (func(next http.Handler) http.Handler, error) {
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAPI router: %w", err)
+	}
+
+	options := &openapi3filter.Options{
+		IncludeResponseStatus: true,
+		// Leave the query as sent so handlers and ETags see the same request
+		SkipSettingDefaults: true,
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			}
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid parameters: %v", requestErrorMessage(err)))
+				return
+			}
+
+			rec := &recorder{header: make(http.Header), status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if err := validateResponse(r.Context(), input, rec); err != nil {
+				writeError(w, http.StatusInternalServerError, fmt.Sprintf("Response does not match the API contract: %v", err))
+				return
+			}
+
+			for k, v := range rec.header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes())
+		})
+	}, nil
+}
+
+// validateResponse checks the status and headers of a recorded response,
+// and its body too when it is JSON
+func validateResponse(ctx context.Context, input *openapi3filter.RequestValidationInput, rec *recorder) error {
+	options := *input.Options
+	mediaType, _, _ := mime.ParseMediaType(rec.header.Get("Content-Type"))
+	options.ExcludeResponseBody = mediaType != "application/json"
+
+	responseInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 rec.status,
+		Header:                 rec.header,
+		Options:                &options,
+	}
+	responseInput.SetBodyBytes(rec.body.Bytes())
+	return openapi3filter.ValidateResponse(ctx, responseInput)
+}
+
+// requestErrorMessage drops the operation prefix and schema dump kin-openapi
+// puts on parameter errors, keeping the part a client can act on
+func requestErrorMessage(err error) string {
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) && requestErr.Parameter != nil {
+		var schemaErr *openapi3.SchemaError
+		if errors.As(requestErr.Err, &schemaErr) {
+			return fmt.Sprintf("%s: %s", requestErr.Parameter.Name, schemaErr.Reason)
+		}
+		return fmt.Sprintf("%s: %v", requestErr.Parameter.Name, requestErr.Err)
+	}
+	var routeErr *routers.RouteError
+	if errors.As(err, &routeErr) {
+		return routeErr.Reason
+	}
+	return err.Error()
+}
+
+// writeError writes an error in the API's {"error": "..."} form
+func writeError(w http.ResponseWriter, status int, message string) {
+	body, _ := json.Marshal(map[string]string{"error": message})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// recorder buffers a response until it has been validated
+type recorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *recorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *recorder) WriteHeader(code int) {
+	if !rec.wroteHeader {
+		rec.wroteHeader = true
+		rec.status = code
+	}
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return rec.body.Write(b)
+}
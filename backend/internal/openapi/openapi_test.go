@@ -0,0 +1,100 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// validated serves body as JSON through the validator and returns the status
+func validated(t *testing.T, status int, body []byte, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	doc, err := Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+	validator, err := Validator(doc)
+	if err != nil {
+		t.Fatalf("Validator() unexpected error = %v", err)
+	}
+
+	handler := validator(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestLoad(t *testing.T) {
+	doc, err := Load()
+	if err != nil {
+		t.Fatalf("Load() unexpected error = %v", err)
+	}
+	if doc.Paths.Find(Path) == nil {
+		t.Errorf("spec does not describe %s", Path)
+	}
+}
+
+// The spec-examples fixtures are the contract's reference responses
+func TestSpecExamplesConform(t *testing.T) {
+	tests := []struct {
+		file   string
+		target string
+	}{
+		{"meta-years.json", "/api/meta/years"},
+		{"countries.json", "/api/meta/countries"},
+		{"names-list.json", "/api/names"},
+		{"names-list-empty.json", "/api/names?name_glob=zz*"},
+		{"name-detail.json", "/api/names/trend?name=Alex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("../../../spec-examples", tt.file))
+			if err != nil {
+				t.Fatalf("ReadFile() unexpected error = %v", err)
+			}
+			if rec := validated(t, http.StatusOK, body, tt.target); rec.Code != http.StatusOK {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, http.StatusOK, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestValidator(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		target     string
+		wantStatus int
+		wantError  string
+	}{
+		{"valid", http.StatusOK, `{"min_year": 1880, "max_year": 2023}`, "/api/meta/years", http.StatusOK, ""},
+		{"invalid parameter", http.StatusOK, `{}`, "/api/names?page_size=5", http.StatusBadRequest, "Invalid parameters: page_size: number must be at least 10"},
+		{"unknown enum value", http.StatusOK, `{}`, "/api/names?sort_order=up", http.StatusBadRequest, "Invalid parameters: sort_order"},
+		{"missing required parameter", http.StatusOK, `{}`, "/api/names/trend", http.StatusBadRequest, "Invalid parameters: name"},
+		{"missing field", http.StatusOK, `{"min_year": 1880}`, "/api/meta/years", http.StatusInternalServerError, "does not match the API contract"},
+		{"undeclared status", http.StatusTeapot, `{"error": "x"}`, "/api/meta/years", http.StatusInternalServerError, "does not match the API contract"},
+		{"declared error", http.StatusNotImplemented, `{"error": "x"}`, "/api/meta/years", http.StatusNotImplemented, ""},
+		{"path outside the spec", http.StatusOK, `{}`, "/unknown", http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := validated(t, tt.status, []byte(tt.body), tt.target)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantError) {
+				t.Errorf("body = %s, want it to contain %q", rec.Body.String(), tt.wantError)
+			}
+		})
+	}
+}
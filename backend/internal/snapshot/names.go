@@ -0,0 +1,695 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/supercakecrumb/nomia/internal/db"
+	"modernc.org/sqlite"
+)
+
+func init() {
+	// The Wilson score bounds of migration 005, on the same Go implementation
+	// the API uses for gender_balance_low/high
+	sqlite.MustRegisterDeterministicScalarFunction("wilson_lower", 2, wilsonBound(false))
+	sqlite.MustRegisterDeterministicScalarFunction("wilson_upper", 2, wilsonBound(true))
+}
+
+// wilsonBound returns a SQL function of (successes, trials) giving the lower
+// or upper 95% Wilson bound as a fraction, NULL without trials
+func wilsonBound(upper bool) func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+	return func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		if args[0] == nil || args[1] == nil {
+			return nil, nil
+		}
+		successes, ok1 := args[0].(int64)
+		trials, ok2 := args[1].(int64)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("wilson bounds take integer counts")
+		}
+		low, high, ok := db.GenderBalanceInterval(int(successes), int(trials-successes))
+		if !ok {
+			return nil, nil
+		}
+		if upper {
+			return high / 100, nil
+		}
+		return low / 100, nil
+	}
+}
+
+// namesListQuery is the 6-stage names list pipeline of *db.DB in SQLite,
+// over the per-dataset name_counts rows summed per name, country and year.
+// regr_slope and stddev_pop are computed from least-squares sums. :limit
+// -1 returns every row and :after resumes after a cursor.
+const namesListQuery = `
+	WITH
+	-- Stage 1: Basic Filters
+	filtered_names AS (
+		SELECT
+			n.name,
+			n.year,
+			SUM(n.female_count + n.male_count + n.unknown_count) as total_count,
+			SUM(n.female_count) as female_count,
+			SUM(n.male_count) as male_count,
+			n.country_code,
+			b.total_births
+		FROM name_counts n
+		LEFT JOIN births b ON b.country_code = n.country_code AND b.year = n.year
+		WHERE n.year >= :year_from
+		  AND n.year <= :year_to
+		  AND (:countries IS NULL OR n.country_code IN (SELECT value FROM json_each(:countries)))
+		  AND (:pattern = '' OR n.name LIKE :pattern)
+		GROUP BY n.name, n.country_code, n.year, b.total_births
+	),
+	-- Number of selected country-years with births totals (normalization denominator)
+	birth_cells AS (
+		SELECT COUNT(*) as cells
+		FROM births b
+		WHERE b.year >= :year_from
+		  AND b.year <= :year_to
+		  AND (:countries IS NULL OR b.country_code IN (SELECT value FROM json_each(:countries)))
+	),
+	-- All births per selected year (peak share denominator)
+	year_births AS (
+		SELECT b.year, SUM(b.total_births) as births
+		FROM births b
+		WHERE b.year >= :year_from
+		  AND b.year <= :year_to
+		  AND (:countries IS NULL OR b.country_code IN (SELECT value FROM json_each(:countries)))
+		GROUP BY b.year
+	),
+	-- Stage 1b: Rows the gender balance filter is evaluated on: the separate
+	-- balance window when set, otherwise the popularity window rows when a
+	-- per-country scope needs them (empty for the plain pooled filter)
+	balance_rows AS (
+		SELECT name, country_code, female_count, male_count
+		FROM filtered_names
+		WHERE :balance_year_from = 0 AND :balance_scope <> 'pooled'
+		UNION ALL
+		SELECT n.name, n.country_code, n.female_count, n.male_count
+		FROM name_counts n
+		WHERE :balance_year_from > 0
+		  AND n.year >= :balance_year_from
+		  AND n.year <= :balance_year_to
+		  AND (:countries IS NULL OR n.country_code IN (SELECT value FROM json_each(:countries)))
+		  AND (:pattern = '' OR n.name LIKE :pattern)
+	),
+	balance_window_counts AS (
+		SELECT
+			name,
+			SUM(male_count) as male_count,
+			SUM(female_count + male_count) as binary_count
+		FROM balance_rows
+		GROUP BY name
+	),
+	-- window_in_range is NULL when the name has no binary data in the window
+	balance_window AS (
+		SELECT
+			name,
+			100.0 * male_count / NULLIF(binary_count, 0) as window_balance,
+			CASE
+				WHEN binary_count = 0 THEN NULL
+				WHEN :balance_estimate = 'interval' THEN
+					100 * wilson_lower(male_count, binary_count) >= :balance_min AND 100 * wilson_upper(male_count, binary_count) <= :balance_max
+				ELSE
+					100.0 * male_count / binary_count >= :balance_min AND 100.0 * male_count / binary_count <= :balance_max
+			END as window_in_range
+		FROM balance_window_counts
+	),
+	balance_by_country AS (
+		SELECT
+			name,
+			country_code,
+			SUM(male_count) as male_count,
+			SUM(female_count + male_count) as binary_count
+		FROM balance_rows
+		WHERE :balance_scope <> 'pooled'
+		  AND (:balance_scope = 'every_country' OR country_code = :balance_country)
+		GROUP BY name, country_code
+	),
+	-- NULL when the name has no binary data in any considered country
+	country_check AS (
+		SELECT
+			name,
+			MIN(CASE
+				WHEN :balance_estimate = 'interval' THEN
+					100 * wilson_lower(male_count, binary_count) >= :balance_min AND 100 * wilson_upper(male_count, binary_count) <= :balance_max
+				ELSE
+					100.0 * male_count / binary_count >= :balance_min AND 100.0 * male_count / binary_count <= :balance_max
+			END) as in_range
+		FROM balance_by_country
+		WHERE binary_count > 0
+		GROUP BY name
+	),
+	-- Stage 2: Aggregation
+	aggregated AS (
+		SELECT
+			name,
+			SUM(total_count) as total_count,
+			SUM(female_count) as female_count,
+			SUM(male_count) as male_count,
+			100.0 * SUM(male_count) / NULLIF(SUM(female_count + male_count), 0) as gender_balance,
+			MIN(year) as name_start,
+			MAX(year) as name_end,
+			group_concat(DISTINCT country_code ORDER BY country_code) as countries,
+			CASE WHEN :normalize = 'per_births' THEN
+				SUM(100000.0 * total_count / NULLIF(total_births, 0)) / NULLIF((SELECT cells FROM birth_cells), 0)
+			END as normalized_count
+		FROM filtered_names
+		GROUP BY name
+	),
+	-- Stage 2b: Per-year gender balance for drift metrics
+	yearly AS (
+		SELECT
+			name,
+			year,
+			SUM(total_count) as total_count,
+			SUM(male_count) as male_count,
+			SUM(female_count + male_count) as binary_count,
+			100.0 * SUM(male_count) / NULLIF(SUM(female_count + male_count), 0) as gender_balance,
+			MIN(year) OVER (PARTITION BY name) as first_year,
+			MAX(year) OVER (PARTITION BY name) as last_year
+		FROM filtered_names
+		GROUP BY name, year
+	),
+	-- Least-squares sums of the yearly balance against the year (counted
+	-- from the first year to keep them small)
+	drift_fit AS (
+		SELECT
+			name,
+			COUNT(*) as n,
+			SUM(year - first_year) as sx,
+			SUM(gender_balance) as sy,
+			SUM((year - first_year) * (year - first_year)) as sxx,
+			SUM((year - first_year) * gender_balance) as sxy,
+			SUM(gender_balance * gender_balance) as syy
+		FROM yearly
+		WHERE gender_balance IS NOT NULL
+		GROUP BY name
+	),
+	drift_stats AS (
+		SELECT
+			y.name,
+			10 * (f.n * f.sxy - f.sx * f.sy) / NULLIF(f.n * f.sxx - f.sx * f.sx, 0) as drift,
+			100.0 * SUM(y.male_count) FILTER (WHERE y.year <= y.first_year + 9) /
+				NULLIF(SUM(y.binary_count) FILTER (WHERE y.year <= y.first_year + 9), 0) as early_balance,
+			100.0 * SUM(y.male_count) FILTER (WHERE y.year >= y.last_year - 9) /
+				NULLIF(SUM(y.binary_count) FILTER (WHERE y.year >= y.last_year - 9), 0) as late_balance,
+			CASE WHEN f.n >= 2 THEN sqrt(MAX(0.0, f.syy / f.n - (f.sy / f.n) * (f.sy / f.n))) END as balance_volatility
+		FROM yearly y
+		LEFT JOIN drift_fit f ON f.name = y.name
+		GROUP BY y.name
+	),
+	-- Stage 2c: Peak era from the per-year share of births
+	yearly_share AS (
+		SELECT
+			y.name,
+			y.year,
+			y.total_count,
+			1.0 * y.total_count / NULLIF(yb.births, 0) as share,
+			ROW_NUMBER() OVER (
+				PARTITION BY y.name
+				ORDER BY 1.0 * y.total_count / NULLIF(yb.births, 0) DESC NULLS LAST, y.total_count DESC, y.year ASC
+			) as share_order,
+			SUM(y.total_count) OVER (PARTITION BY y.name ORDER BY y.year) as cumulative_count,
+			SUM(y.total_count) OVER (PARTITION BY y.name) as name_total
+		FROM yearly y
+		LEFT JOIN year_births yb ON yb.year = y.year
+	),
+	peak_stats AS (
+		SELECT
+			name,
+			MAX(CASE WHEN share_order = 1 THEN year END) as peak_year,
+			MAX(share) as peak_share,
+			MIN(year) FILTER (WHERE cumulative_count >= 0.1 * name_total) as era_start,
+			MIN(year) FILTER (WHERE cumulative_count >= 0.9 * name_total) as era_end
+		FROM yearly_share
+		GROUP BY name
+	),
+	with_drift AS (
+		SELECT
+			a.*,
+			d.drift,
+			d.early_balance,
+			d.late_balance,
+			d.balance_volatility,
+			bw.window_balance,
+			bw.window_in_range,
+			cc.in_range as country_in_range,
+			ps.peak_year,
+			ps.peak_share,
+			ps.era_start,
+			ps.era_end
+		FROM aggregated a
+		LEFT JOIN drift_stats d ON d.name = a.name
+		LEFT JOIN peak_stats ps ON ps.name = a.name
+		LEFT JOIN balance_window bw ON bw.name = a.name
+		LEFT JOIN country_check cc ON cc.name = a.name
+	),
+	-- Stage 3: Gender Balance Filter
+	-- With a balance window or a per-country scope the name must have
+	-- binary data inside it
+	gender_filtered AS (
+		SELECT *
+		FROM with_drift
+		WHERE CASE
+				WHEN :balance_scope <> 'pooled' THEN country_in_range
+				WHEN :balance_year_from > 0 THEN window_in_range
+				WHEN gender_balance IS NULL THEN 1
+				WHEN :balance_estimate = 'interval' THEN
+					100 * wilson_lower(male_count, male_count + female_count) >= :balance_min
+					AND 100 * wilson_upper(male_count, male_count + female_count) <= :balance_max
+				ELSE gender_balance >= :balance_min AND gender_balance <= :balance_max
+			END
+		  AND (:drift_min IS NULL OR drift >= :drift_min)
+		  AND (:drift_max IS NULL OR drift <= :drift_max)
+		  AND (:volatility_max IS NULL OR balance_volatility <= :volatility_max)
+		  AND (:peak_year_min IS NULL OR peak_year >= :peak_year_min)
+		  AND (:peak_year_max IS NULL OR peak_year <= :peak_year_max)
+		  AND (:era_start_min IS NULL OR era_start >= :era_start_min)
+		  AND (:era_end_max IS NULL OR era_end <= :era_end_max)
+	),
+	-- Stage 4: Popularity Computation
+	-- Ranks and coverage use the normalized shares when normalize=per_births
+	popularity AS (
+		SELECT
+			*,
+			CASE WHEN :normalize = 'per_births' THEN COALESCE(normalized_count, 0) ELSE 1.0 * total_count END as popularity_value
+		FROM gender_filtered
+	),
+	ranked AS (
+		SELECT
+			*,
+			ROW_NUMBER() OVER (ORDER BY popularity_value DESC, name ASC) as rank,
+			SUM(popularity_value) OVER (ORDER BY popularity_value DESC, name ASC) as cumulative_value,
+			SUM(total_count) OVER () as population_total,
+			SUM(popularity_value) OVER () as popularity_total
+		FROM popularity
+	),
+	with_cumulative_share AS (
+		SELECT
+			*,
+			cumulative_value / NULLIF(popularity_total, 0) as cumulative_share
+		FROM ranked
+	),
+	-- Stage 5: Popularity Filter
+	popularity_filtered AS (
+		SELECT *
+		FROM with_cumulative_share
+		WHERE
+			CASE
+				WHEN :coverage_percent > 0 THEN cumulative_share <= :coverage_percent / 100.0
+				WHEN :top_n > 0 THEN rank <= :top_n
+				WHEN :min_count > 0 THEN total_count >= :min_count
+				ELSE 1
+			END
+	),
+	-- Count total for pagination
+	total_count AS (
+		SELECT COUNT(*) as cnt FROM popularity_filtered
+	),
+	-- Stage 6: Final Sorting & Pagination
+	-- sort_value is the primary sort key (NULL for sort_key=name); a cursor
+	-- resumes after the (sort_value, total_count, name) it carries
+	sortable AS (
+		SELECT
+			pf.*,
+			tc.cnt as total_count_val,
+			CAST(CASE
+			WHEN :sort_key = 'popularity' AND :sort_order = 'asc' THEN pf.rank
+			WHEN :sort_key = 'popularity' AND :sort_order = 'desc' THEN -pf.rank
+			WHEN :sort_key = 'total_count' AND :sort_order = 'asc' THEN pf.total_count
+			WHEN :sort_key = 'total_count' AND :sort_order = 'desc' THEN -pf.total_count
+			WHEN :sort_key = 'gender_balance' AND :sort_order = 'asc' THEN pf.gender_balance
+			WHEN :sort_key = 'gender_balance' AND :sort_order = 'desc' THEN -pf.gender_balance
+			WHEN :sort_key = 'drift' AND :sort_order = 'asc' THEN pf.drift
+			WHEN :sort_key = 'drift' AND :sort_order = 'desc' THEN -pf.drift
+			WHEN :sort_key = 'volatility' AND :sort_order = 'asc' THEN pf.balance_volatility
+			WHEN :sort_key = 'volatility' AND :sort_order = 'desc' THEN -pf.balance_volatility
+			WHEN :sort_key = 'peak_year' AND :sort_order = 'asc' THEN pf.peak_year
+			WHEN :sort_key = 'peak_year' AND :sort_order = 'desc' THEN -pf.peak_year
+			WHEN :sort_key = 'peak_share' AND :sort_order = 'asc' THEN pf.peak_share
+			WHEN :sort_key = 'peak_share' AND :sort_order = 'desc' THEN -pf.peak_share
+			WHEN :sort_key = 'era_start' AND :sort_order = 'asc' THEN pf.era_start
+			WHEN :sort_key = 'era_start' AND :sort_order = 'desc' THEN -pf.era_start
+			END AS REAL) as sort_value
+		FROM popularity_filtered pf
+		CROSS JOIN total_count tc
+	)
+	SELECT
+		s.name,
+		s.total_count,
+		s.female_count,
+		s.male_count,
+		s.gender_balance,
+		s.name_start,
+		s.name_end,
+		s.countries,
+		s.normalized_count,
+		s.drift,
+		s.early_balance,
+		s.late_balance,
+		s.balance_volatility,
+		s.window_balance,
+		s.peak_year,
+		s.peak_share,
+		s.era_start,
+		s.era_end,
+		s.rank,
+		s.population_total,
+		s.cumulative_share,
+		s.total_count_val,
+		s.sort_value
+	FROM sortable s
+	WHERE NOT :after
+	   OR CASE
+			WHEN :sort_key = 'name' AND :sort_order = 'asc' THEN s.name > :after_name
+			WHEN :sort_key = 'name' THEN s.name < :after_name
+			WHEN :after_sort_value IS NULL THEN
+				s.sort_value IS NULL
+				AND (s.total_count < :after_total_count OR (s.total_count = :after_total_count AND s.name > :after_name))
+			ELSE
+				s.sort_value > :after_sort_value
+				OR s.sort_value IS NULL
+				OR (s.sort_value = :after_sort_value AND (s.total_count < :after_total_count OR (s.total_count = :after_total_count AND s.name > :after_name)))
+		END
+	ORDER BY
+		s.sort_value ASC NULLS LAST,
+		CASE
+			WHEN :sort_key = 'name' AND :sort_order = 'asc' THEN s.name
+		END ASC NULLS LAST,
+		CASE
+			WHEN :sort_key = 'name' AND :sort_order = 'desc' THEN s.name
+		END DESC NULLS LAST,
+		-- Tie breakers
+		s.total_count DESC,
+		s.name ASC
+	LIMIT :limit OFFSET :offset
+`
+
+// namesListRow is one scanned row of namesListQuery
+type namesListRow struct {
+	db.NameRecord
+	totalCount      int // rows matching the filters
+	populationTotal int64
+	sortValue       *float64
+}
+
+func (r *Reader) GetNamesList(ctx context.Context, params *db.NamesListParams) (*db.NamesListResponse, error) {
+	offset := (params.Page - 1) * params.PageSize
+	if params.Cursor != nil {
+		offset = 0 // cursor mode ignores page
+	}
+
+	// One extra row tells whether a next page exists
+	rows, err := r.namesListRows(ctx, params, params.PageSize+1, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []db.NameRecord{}
+	var totalCount int
+	var populationTotal int64
+	var nextCursor string
+	var lastSortValue *float64
+
+	for rows.Next() {
+		row, err := scanNamesListRow(rows, params)
+		if err != nil {
+			return nil, err
+		}
+		totalCount = row.totalCount
+		populationTotal = row.populationTotal
+
+		if len(names) == params.PageSize {
+			// The extra row: the last returned one becomes the next cursor
+			last := names[len(names)-1]
+			nextCursor = db.NamesCursor{
+				SortKey:    params.SortKey,
+				SortOrder:  params.SortOrder,
+				SortValue:  lastSortValue,
+				TotalCount: last.TotalCount,
+				Name:       last.Name,
+				Rank:       last.Rank,
+			}.Encode()
+			break
+		}
+		lastSortValue = row.sortValue
+		names = append(names, row.NameRecord)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows failed: %w", err)
+	}
+	rows.Close()
+
+	// Attach per-country balances for the returned page
+	if err := r.attachCountryBalances(ctx, params, names); err != nil {
+		return nil, fmt.Errorf("failed to get country balances: %w", err)
+	}
+
+	meta := db.NamesListMeta{
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		TotalCount: totalCount,
+		TotalPages: (totalCount + params.PageSize - 1) / params.PageSize,
+		DbStart:    r.yearRange.MinYear,
+		DbEnd:      r.yearRange.MaxYear,
+		NextCursor: nextCursor,
+
+		PopularitySummary: params.PopularitySummary(names, populationTotal),
+	}
+	if params.Cursor != nil {
+		meta.Page = 0
+	}
+
+	return &db.NamesListResponse{
+		Meta:  meta,
+		Names: names,
+	}, nil
+}
+
+func (r *Reader) StreamNamesList(ctx context.Context, params *db.NamesListParams, emit func(db.NameRecord) error) error {
+	unpaged := *params
+	unpaged.Cursor = nil
+
+	rows, err := r.namesListRows(ctx, &unpaged, -1, 0)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row, err := scanNamesListRow(rows, &unpaged)
+		if err != nil {
+			return err
+		}
+		if err := emit(row.NameRecord); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows failed: %w", err)
+	}
+
+	return nil
+}
+
+// namesListRows runs namesListQuery for params; limit -1 returns every row
+func (r *Reader) namesListRows(ctx context.Context, params *db.NamesListParams, limit, offset int) (*sql.Rows, error) {
+	// Convert name_glob to a LIKE pattern
+	globPattern := ""
+	if params.NameGlob != "" {
+		globPattern = strings.ReplaceAll(params.NameGlob, "*", "%")
+		globPattern = strings.ReplaceAll(globPattern, "?", "_")
+	}
+
+	// Keyset position after the cursor's row
+	var after db.NamesCursor
+	if params.Cursor != nil {
+		after = *params.Cursor
+	}
+
+	rows, err := r.sqlite.QueryContext(ctx, namesListQuery,
+		sql.Named("year_from", params.YearFrom),
+		sql.Named("year_to", params.YearTo),
+		sql.Named("countries", countriesArg(params.Countries)),
+		sql.Named("pattern", globPattern),
+		sql.Named("balance_min", params.GenderBalanceMin),
+		sql.Named("balance_max", params.GenderBalanceMax),
+		sql.Named("coverage_percent", params.CoveragePercent),
+		sql.Named("top_n", params.TopN),
+		sql.Named("min_count", params.MinCount),
+		sql.Named("sort_key", params.SortKey),
+		sql.Named("sort_order", params.SortOrder),
+		sql.Named("limit", limit),
+		sql.Named("offset", offset),
+		sql.Named("drift_min", params.DriftMin),
+		sql.Named("drift_max", params.DriftMax),
+		sql.Named("volatility_max", params.VolatilityMax),
+		sql.Named("balance_year_from", params.BalanceYearFrom),
+		sql.Named("balance_year_to", params.BalanceYearTo),
+		sql.Named("balance_scope", params.BalanceScope),
+		sql.Named("balance_country", params.BalanceCountry),
+		sql.Named("normalize", params.Normalize),
+		sql.Named("balance_estimate", params.BalanceEstimate),
+		sql.Named("peak_year_min", params.PeakYearMin),
+		sql.Named("peak_year_max", params.PeakYearMax),
+		sql.Named("era_start_min", params.EraStartMin),
+		sql.Named("era_end_max", params.EraEndMax),
+		sql.Named("after", params.Cursor != nil),
+		sql.Named("after_sort_value", after.SortValue),
+		sql.Named("after_total_count", after.TotalCount),
+		sql.Named("after_name", after.Name),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	return rows, nil
+}
+
+// scanNamesListRow scans the current row of namesListRows
+func scanNamesListRow(rows *sql.Rows, params *db.NamesListParams) (namesListRow, error) {
+	var row namesListRow
+	nr := &row.NameRecord
+	var genderBalance *float64
+	var countries string
+	var drift, earlyBalance, lateBalance, volatility *float64
+	var peakShare *float64
+
+	err := rows.Scan(
+		&nr.Name,
+		&nr.TotalCount,
+		&nr.FemaleCount,
+		&nr.MaleCount,
+		&genderBalance,
+		&nr.NameStart,
+		&nr.NameEnd,
+		&countries,
+		&nr.NormalizedCount,
+		&drift,
+		&earlyBalance,
+		&lateBalance,
+		&volatility,
+		&nr.WindowGenderBalance,
+		&nr.PeakYear,
+		&peakShare,
+		&nr.EraStart,
+		&nr.EraEnd,
+		&nr.Rank,
+		&row.populationTotal,
+		&nr.CumulativeShare,
+		&row.totalCount,
+		&row.sortValue,
+	)
+	if err != nil {
+		return row, fmt.Errorf("scan failed: %w", err)
+	}
+
+	nr.Countries = strings.Split(countries, ",")
+	if genderBalance != nil {
+		nr.GenderBalance = *genderBalance
+	}
+	// total_count sums every sex, so the rest is unknown
+	nr.UnknownCount = nr.TotalCount - nr.FemaleCount - nr.MaleCount
+	nr.HasUnknownData = nr.UnknownCount > 0
+	nr.GenderBalanceLow, nr.GenderBalanceHigh, _ = db.GenderBalanceInterval(nr.MaleCount, nr.FemaleCount)
+	if drift != nil {
+		nr.Drift = *drift
+	}
+	if earlyBalance != nil {
+		nr.EarlyBalance = *earlyBalance
+	}
+	if lateBalance != nil {
+		nr.LateBalance = *lateBalance
+	}
+	if volatility != nil {
+		nr.BalanceVolatility = *volatility
+	}
+	if peakShare != nil {
+		nr.PeakShare = *peakShare
+	}
+	nr.YearsSincePeak = params.YearTo - nr.PeakYear
+
+	return row, nil
+}
+
+// attachCountryBalances fills CountryBalances for the given page of names,
+// computed over the balance window (or the popularity window when unset)
+func (r *Reader) attachCountryBalances(ctx context.Context, params *db.NamesListParams, names []db.NameRecord) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	// Matching without case uses the name index; names differing from the
+	// page's only in case are dropped below
+	query := `
+		SELECT
+			n.name,
+			n.country_code,
+			SUM(n.female_count) as female_count,
+			SUM(n.male_count) as male_count,
+			100.0 * SUM(n.male_count) / NULLIF(SUM(n.female_count + n.male_count), 0) as gender_balance
+		FROM name_counts n
+		WHERE n.name COLLATE NOCASE IN (SELECT value FROM json_each(:names))
+		  AND n.year >= :year_from
+		  AND n.year <= :year_to
+		  AND (:countries IS NULL OR n.country_code IN (SELECT value FROM json_each(:countries)))
+		GROUP BY n.name, n.country_code
+		ORDER BY n.name, n.country_code
+	`
+
+	yearFrom, yearTo := params.YearFrom, params.YearTo
+	if params.HasBalanceWindow() {
+		yearFrom, yearTo = params.BalanceYearFrom, params.BalanceYearTo
+	}
+
+	nameList := make([]string, len(names))
+	for i, nr := range names {
+		nameList[i] = nr.Name
+	}
+	nameArg, _ := json.Marshal(nameList)
+
+	rows, err := r.sqlite.QueryContext(ctx, query,
+		sql.Named("names", string(nameArg)),
+		sql.Named("year_from", yearFrom),
+		sql.Named("year_to", yearTo),
+		sql.Named("countries", countriesArg(params.Countries)),
+	)
+	if err != nil {
+		return fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	byName := make(map[string][]db.CountryBalance)
+	for rows.Next() {
+		var name string
+		var cb db.CountryBalance
+		var gb *float64
+		if err := rows.Scan(&name, &cb.CountryCode, &cb.FemaleCount, &cb.MaleCount, &gb); err != nil {
+			return fmt.Errorf("scan failed: %w", err)
+		}
+		if gb != nil {
+			cb.GenderBalance = *gb
+		}
+		byName[name] = append(byName[name], cb)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows failed: %w", err)
+	}
+
+	for i := range names {
+		names[i].CountryBalances = byName[names[i].Name]
+		if names[i].CountryBalances == nil {
+			names[i].CountryBalances = []db.CountryBalance{}
+		}
+	}
+
+	return nil
+}
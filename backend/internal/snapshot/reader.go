@@ -0,0 +1,298 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/supercakecrumb/nomia/internal/db"
+)
+
+// Reader answers the read queries of *db.DB from a snapshot file, computing
+// in SQLite what PostgreSQL computes. Names are matched with SQLite LIKE and
+// NOCASE, which fold ASCII case only. Trending names, comparisons, age
+// distributions and similar trajectories read tables a snapshot does not
+// hold and return db.ErrUnsupported, as do per-year ranks, which trends
+// leave out.
+type Reader struct {
+	sqlite *sql.DB
+
+	dataVersion int64
+	yearRange   db.YearRange
+	countries   []db.Country
+}
+
+var _ db.Store = (*Reader)(nil)
+
+// Open opens the snapshot at path read-only and loads its small tables
+func Open(path string) (*Reader, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("unable to open snapshot: %w", err)
+	}
+
+	// immutable skips locking: nothing writes a snapshot once it is in place
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() + "?mode=ro&immutable=1"
+	sqlite, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open snapshot: %w", err)
+	}
+
+	r := &Reader{sqlite: sqlite}
+	if err := r.load(context.Background()); err != nil {
+		sqlite.Close()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *Reader) Close() {
+	r.sqlite.Close()
+}
+
+// load checks the format and reads meta, the year range and countries
+func (r *Reader) load(ctx context.Context) error {
+	meta := make(map[string]string)
+	rows, err := r.sqlite.QueryContext(ctx, `SELECT key, value FROM meta`)
+	if err != nil {
+		return fmt.Errorf("not a snapshot: %w", err)
+	}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			rows.Close()
+			return fmt.Errorf("meta scan failed: %w", err)
+		}
+		meta[key] = value
+	}
+	rows.Close()
+	if meta["format"] != Format {
+		return fmt.Errorf("not a snapshot: format is %q", meta["format"])
+	}
+	if meta["format_version"] != FormatVersion {
+		return fmt.Errorf("snapshot format version %s is not supported (want %s)", meta["format_version"], FormatVersion)
+	}
+	r.dataVersion, err = strconv.ParseInt(meta["data_version"], 10, 64)
+	if err != nil {
+		return fmt.Errorf("snapshot data version is invalid: %w", err)
+	}
+
+	// Year range
+	var minYear, maxYear sql.NullInt64
+	err = r.sqlite.QueryRowContext(ctx, `SELECT MIN(year), MAX(year) FROM name_counts`).Scan(&minYear, &maxYear)
+	if err != nil {
+		return fmt.Errorf("year range query failed: %w", err)
+	}
+	r.yearRange = db.YearRange{MinYear: int(minYear.Int64), MaxYear: int(maxYear.Int64)}
+
+	// Countries
+	rows, err = r.sqlite.QueryContext(ctx, `
+		SELECT
+			code,
+			name,
+			data_source_name,
+			data_source_url,
+			data_source_description,
+			data_source_requires_manual_download
+		FROM countries
+		ORDER BY name
+	`)
+	if err != nil {
+		return fmt.Errorf("countries query failed: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c db.Country
+		err := rows.Scan(
+			&c.Code,
+			&c.Name,
+			&c.DataSourceName,
+			&c.DataSourceURL,
+			&c.DataSourceDescription,
+			&c.DataSourceRequiresManualDownload,
+		)
+		if err != nil {
+			return fmt.Errorf("countries scan failed: %w", err)
+		}
+		r.countries = append(r.countries, c)
+	}
+
+	return rows.Err()
+}
+
+// Ping checks the snapshot file is readable
+func (r *Reader) Ping(ctx context.Context) error {
+	return r.sqlite.PingContext(ctx)
+}
+
+// CacheStats returns nil: snapshot reads are not cached
+func (r *Reader) CacheStats() *db.CacheStats {
+	return nil
+}
+
+// DataVersion returns the data version of the database the snapshot was
+// taken from
+func (r *Reader) DataVersion(ctx context.Context) (int64, error) {
+	return r.dataVersion, nil
+}
+
+func (r *Reader) GetYearRange(ctx context.Context) (*db.YearRange, error) {
+	yr := r.yearRange
+	return &yr, nil
+}
+
+func (r *Reader) GetCountries(ctx context.Context) (*db.CountriesResponse, error) {
+	return &db.CountriesResponse{Countries: append([]db.Country(nil), r.countries...)}, nil
+}
+
+func (r *Reader) GetDatasetVersions(ctx context.Context) ([]db.DatasetVersion, error) {
+	query := `
+		SELECT
+			country_code,
+			COUNT(*),
+			MIN(year_from),
+			MAX(year_to),
+			MAX(parsed_at)
+		FROM datasets
+		WHERE parse_status = 'parsed'
+		GROUP BY country_code
+		ORDER BY country_code
+	`
+
+	rows, err := r.sqlite.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []db.DatasetVersion{}
+	for rows.Next() {
+		var v db.DatasetVersion
+		var parsedAt string
+		if err := rows.Scan(&v.Country, &v.Datasets, &v.YearFrom, &v.YearTo, &parsedAt); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		if v.LastParsedAt, err = time.Parse(time.RFC3339, parsedAt); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows failed: %w", err)
+	}
+
+	return versions, nil
+}
+
+// countriesArg returns a country filter as the JSON array the queries read
+// with json_each, or nil for all countries
+func countriesArg(countries []string) interface{} {
+	if len(countries) == 0 {
+		return nil
+	}
+	b, _ := json.Marshal(countries)
+	return string(b)
+}
+
+// countsFilter builds the WHERE clause over name_counts n. Names match
+// exactly, without case.
+func countsFilter(names []string, yearFrom, yearTo int, countries []string) (string, []interface{}) {
+	where := []string{"n.year >= ?", "n.year <= ?"}
+	args := []interface{}{yearFrom, yearTo}
+
+	if len(names) > 0 {
+		where = append(where, "n.name COLLATE NOCASE IN (?"+strings.Repeat(", ?", len(names)-1)+")")
+		for _, name := range names {
+			args = append(args, name)
+		}
+	}
+
+	if len(countries) > 0 {
+		where = append(where, "n.country_code IN (?"+strings.Repeat(", ?", len(countries)-1)+")")
+		for _, c := range countries {
+			args = append(args, c)
+		}
+	}
+
+	return strings.Join(where, " AND "), args
+}
+
+func (r *Reader) StreamTrendExport(ctx context.Context, params *db.TrendExportParams, emit func(db.TrendExportRow) error) error {
+	where, args := countsFilter(params.Names, params.YearFrom, params.YearTo, params.Countries)
+	query := `
+		SELECT
+			n.name,
+			n.country_code,
+			n.year,
+			SUM(n.female_count + n.male_count + n.unknown_count) as total_count,
+			SUM(n.female_count) as female_count,
+			SUM(n.male_count) as male_count,
+			SUM(n.unknown_count) as unknown_count,
+			d.id,
+			c.data_source_name,
+			COALESCE(d.source_url, c.data_source_url),
+			d.source_file_name
+		FROM name_counts n
+		JOIN countries c ON n.country_code = c.code
+		JOIN datasets d ON n.dataset_id = d.id
+		WHERE ` + where + `
+		GROUP BY n.name, n.country_code, n.year, d.id
+		ORDER BY LOWER(n.name), n.name, n.country_code, n.year, d.id
+	`
+
+	rows, err := r.sqlite.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tr db.TrendExportRow
+		err := rows.Scan(
+			&tr.Name,
+			&tr.CountryCode,
+			&tr.Year,
+			&tr.TotalCount,
+			&tr.FemaleCount,
+			&tr.MaleCount,
+			&tr.UnknownCount,
+			&tr.DatasetID,
+			&tr.SourceName,
+			&tr.SourceURL,
+			&tr.SourceFileName,
+		)
+		if err != nil {
+			return fmt.Errorf("scan failed: %w", err)
+		}
+		if err := emit(tr); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Reader) GetTrendingNames(ctx context.Context, params *db.TrendingParams) (*db.TrendingResponse, error) {
+	return nil, db.ErrUnsupported
+}
+
+func (r *Reader) GetNamesCompare(ctx context.Context, params *db.CompareParams) (*db.CompareResponse, error) {
+	return nil, db.ErrUnsupported
+}
+
+func (r *Reader) GetAgeDistribution(ctx context.Context, params *db.AgeDistributionParams) (*db.AgeDistributionResponse, error) {
+	return nil, db.ErrUnsupported
+}
+
+func (r *Reader) GetSimilarTrajectories(ctx context.Context, params *db.SimilarTrajectoryParams) (*db.SimilarTrajectoryResponse, error) {
+	return nil, db.ErrUnsupported
+}
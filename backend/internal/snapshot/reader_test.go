@@ -0,0 +1,274 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/supercakecrumb/nomia/internal/db"
+)
+
+// writeTestSnapshot builds a small snapshot file the way Write lays it out
+func writeTestSnapshot(t *testing.T, format string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	lite, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("sql.Open() unexpected error = %v", err)
+	}
+	defer lite.Close()
+
+	statements := []string{
+		schema,
+		`INSERT INTO meta VALUES ('format', '` + format + `'), ('format_version', '1'), ('data_version', '7')`,
+		`INSERT INTO countries VALUES
+			('US', 'United States', 'Social Security Administration', 'https://www.ssa.gov', '', 0),
+			('SE', 'Sweden', 'Statistics Sweden', 'https://www.scb.se', '', 1)`,
+		`INSERT INTO datasets VALUES
+			(1, 'US', 'yob2000.txt', NULL, 2000, 2001, 5, 'parsed', '2025-01-02T03:04:05Z'),
+			(2, 'SE', 'names.csv', 'https://www.scb.se/names', 2001, 2001, 0, 'parsed', '2025-02-01T00:00:00Z')`,
+		`INSERT INTO name_counts VALUES
+			('Emma', 'US', 2000, 1, 900, 0, 0),
+			('Emma', 'US', 2001, 1, 1000, 10, 0),
+			('Emma', 'SE', 2001, 2, 100, 0, 0),
+			('Noah', 'US', 2000, 1, 0, 800, 0),
+			('Alex', 'US', 2000, 1, 200, 300, 0),
+			('Alex', 'SE', 2001, 2, 60, 40, 0)`,
+		`INSERT INTO births VALUES ('US', 2000, 10000), ('US', 2001, 10000), ('SE', 2001, 1000)`,
+		indexes,
+	}
+	for _, s := range statements {
+		if _, err := lite.Exec(s); err != nil {
+			t.Fatalf("Exec() unexpected error = %v", err)
+		}
+	}
+
+	return path
+}
+
+func TestOpen(t *testing.T) {
+	r, err := Open(writeTestSnapshot(t, Format))
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer r.Close()
+	ctx := context.Background()
+
+	if v, _ := r.DataVersion(ctx); v != 7 {
+		t.Errorf("DataVersion() = %d, want 7", v)
+	}
+	if yr, _ := r.GetYearRange(ctx); *yr != (db.YearRange{MinYear: 2000, MaxYear: 2001}) {
+		t.Errorf("GetYearRange() = %+v, want 2000-2001", yr)
+	}
+
+	countries, _ := r.GetCountries(ctx)
+	if len(countries.Countries) != 2 || countries.Countries[0].Name != "Sweden" || !countries.Countries[0].DataSourceRequiresManualDownload {
+		t.Errorf("GetCountries() = %+v, want Sweden first with manual download", countries.Countries)
+	}
+
+	versions, err := r.GetDatasetVersions(ctx)
+	if err != nil {
+		t.Fatalf("GetDatasetVersions() unexpected error = %v", err)
+	}
+	if len(versions) != 2 || versions[1].Country != "US" || versions[1].LastParsedAt.Year() != 2025 {
+		t.Errorf("GetDatasetVersions() = %+v", versions)
+	}
+}
+
+func TestOpenRejectsOtherFormats(t *testing.T) {
+	if _, err := Open(writeTestSnapshot(t, "something-else")); err == nil {
+		t.Error("Open() expected error for another format")
+	}
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Error("Open() expected error for a missing file")
+	}
+}
+
+func TestReaderNamesList(t *testing.T) {
+	r, err := Open(writeTestSnapshot(t, Format))
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer r.Close()
+
+	tests := []struct {
+		query url.Values
+		want  []string
+	}{
+		{url.Values{}, []string{"Emma", "Noah", "Alex"}},
+		{url.Values{"name_glob": {"a*"}}, []string{"Alex"}},
+		{url.Values{"countries": {"SE"}}, []string{"Alex", "Emma"}},
+		{url.Values{"year_min": {"2001"}}, []string{"Emma", "Alex"}},
+		{url.Values{"gender_balance_min": {"50"}, "balance_estimate": {"interval"}}, []string{"Noah", "Alex"}},
+		{url.Values{"drift_max": {"0"}}, []string{"Alex"}},
+		{url.Values{"sort_key": {"peak_share"}, "sort_order": {"asc"}}, []string{"Alex", "Noah", "Emma"}},
+	}
+
+	for _, tt := range tests {
+		params, err := db.ParseNamesListParams(tt.query, 2000, 2001)
+		if err != nil {
+			t.Fatalf("ParseNamesListParams(%v) unexpected error = %v", tt.query, err)
+		}
+		resp, err := r.GetNamesList(context.Background(), params)
+		if err != nil {
+			t.Fatalf("GetNamesList(%v) unexpected error = %v", tt.query, err)
+		}
+		var got []string
+		for _, nr := range resp.Names {
+			got = append(got, nr.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetNamesList(%v) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestReaderNamesListMetrics(t *testing.T) {
+	r, err := Open(writeTestSnapshot(t, Format))
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer r.Close()
+
+	params, err := db.ParseNamesListParams(url.Values{"name_glob": {"alex"}}, 2000, 2001)
+	if err != nil {
+		t.Fatalf("ParseNamesListParams() unexpected error = %v", err)
+	}
+	resp, err := r.GetNamesList(context.Background(), params)
+	if err != nil {
+		t.Fatalf("GetNamesList() unexpected error = %v", err)
+	}
+	if len(resp.Names) != 1 {
+		t.Fatalf("GetNamesList() returned %d names, want 1", len(resp.Names))
+	}
+
+	// Alex: 60% male of 500 in 2000 (5% of births), 40% of 100 in 2001
+	nr := resp.Names[0]
+	near := func(got, want float64) bool { return math.Abs(got-want) < 1e-9 }
+	if nr.TotalCount != 600 || !near(nr.GenderBalance, 340.0/6) || !reflect.DeepEqual(nr.Countries, []string{"SE", "US"}) {
+		t.Errorf("totals = %d, %v, %v, want 600, 56.67, [SE US]", nr.TotalCount, nr.GenderBalance, nr.Countries)
+	}
+	if !near(nr.Drift, -200) || !near(nr.BalanceVolatility, 10) || !near(nr.EarlyBalance, 340.0/6) {
+		t.Errorf("drift = %v, volatility = %v, early = %v, want -200, 10, 56.67", nr.Drift, nr.BalanceVolatility, nr.EarlyBalance)
+	}
+	if nr.PeakYear != 2000 || !near(nr.PeakShare, 0.05) || nr.EraStart != 2000 || nr.EraEnd != 2001 {
+		t.Errorf("peak = %d (%v), era = %d-%d, want 2000 (0.05), 2000-2001", nr.PeakYear, nr.PeakShare, nr.EraStart, nr.EraEnd)
+	}
+	if len(nr.CountryBalances) != 2 || !near(nr.CountryBalances[0].GenderBalance, 40) {
+		t.Errorf("country balances = %+v, want SE at 40 then US", nr.CountryBalances)
+	}
+}
+
+func TestReaderNamesListCursor(t *testing.T) {
+	r, err := Open(writeTestSnapshot(t, Format))
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer r.Close()
+
+	var got []string
+	query := url.Values{}
+	for len(got) < 5 {
+		params, err := db.ParseNamesListParams(query, 2000, 2001)
+		if err != nil {
+			t.Fatalf("ParseNamesListParams(%v) unexpected error = %v", query, err)
+		}
+		params.PageSize = 1
+		resp, err := r.GetNamesList(context.Background(), params)
+		if err != nil {
+			t.Fatalf("GetNamesList(%v) unexpected error = %v", query, err)
+		}
+		for _, nr := range resp.Names {
+			got = append(got, nr.Name)
+		}
+		if resp.Meta.NextCursor == "" {
+			break
+		}
+		query.Set("cursor", resp.Meta.NextCursor)
+	}
+
+	if want := []string{"Emma", "Noah", "Alex"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pages = %v, want %v", got, want)
+	}
+}
+
+func TestReaderNameTrend(t *testing.T) {
+	r, err := Open(writeTestSnapshot(t, Format))
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer r.Close()
+
+	params := &db.NameTrendParams{Name: "ALEX", YearFrom: 2000, YearTo: 2001, Interval: 1, Suppression: "none"}
+	resp, err := r.GetNameTrend(context.Background(), params)
+	if err != nil {
+		t.Fatalf("GetNameTrend() unexpected error = %v", err)
+	}
+
+	if resp.Summary.TotalCount != 600 || len(resp.TimeSeries) != 2 {
+		t.Fatalf("GetNameTrend() summary = %+v with %d points, want 600 over 2", resp.Summary, len(resp.TimeSeries))
+	}
+	// The US dataset suppresses counts below 5 and lists no Alex in 2001
+	if p := resp.TimeSeries[1]; !p.Censored || p.CensoredFemaleMax != 4 || p.CensoredMaleMax != 4 {
+		t.Errorf("GetNameTrend() 2001 point = %+v, want censored with up to 4 per sex", p)
+	}
+	if len(resp.ByCountry) != 2 || resp.ByCountry[0].CountryName != "United States" {
+		t.Errorf("GetNameTrend() by country = %+v, want United States first", resp.ByCountry)
+	}
+}
+
+func TestReaderTrendExport(t *testing.T) {
+	r, err := Open(writeTestSnapshot(t, Format))
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer r.Close()
+
+	params := &db.TrendExportParams{Names: []string{"emma"}, YearFrom: 2000, YearTo: 2001}
+	var rows []db.TrendExportRow
+	err = r.StreamTrendExport(context.Background(), params, func(tr db.TrendExportRow) error {
+		rows = append(rows, tr)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamTrendExport() unexpected error = %v", err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("StreamTrendExport() returned %d rows, want 3", len(rows))
+	}
+	if rows[0].CountryCode != "SE" || rows[0].SourceURL != "https://www.scb.se/names" {
+		t.Errorf("first row = %+v, want SE with the dataset URL", rows[0])
+	}
+	if rows[1].SourceURL != "https://www.ssa.gov" || rows[2].TotalCount != 1010 {
+		t.Errorf("US rows = %+v, want the country URL and 1010 in 2001", rows[1:])
+	}
+
+	// Names are matched exactly, so LIKE wildcards match nothing
+	params.Names = []string{"Em%", "_mma"}
+	err = r.StreamTrendExport(context.Background(), params, func(tr db.TrendExportRow) error {
+		t.Errorf("StreamTrendExport() emitted %+v for wildcard names, want no rows", tr)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamTrendExport() unexpected error = %v", err)
+	}
+}
+
+func TestReaderUnsupported(t *testing.T) {
+	r, err := Open(writeTestSnapshot(t, Format))
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer r.Close()
+
+	if _, err := r.GetTrendingNames(context.Background(), &db.TrendingParams{}); !errors.Is(err, db.ErrUnsupported) {
+		t.Errorf("GetTrendingNames() error = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,67 @@
+// Package snapshot writes the aggregated Nomia dataset to a portable SQLite
+// file and serves the read API from it without PostgreSQL.
+package snapshot
+
+// Format identifies snapshot files; FormatVersion changes whenever the schema
+// does, and readers refuse other versions
+const (
+	Format        = "nomia-snapshot"
+	FormatVersion = "1"
+)
+
+// schema creates the snapshot tables. name_counts holds the births per name,
+// country, year and dataset with one column per sex, the same grain as the
+// names table pivoted by gender.
+const schema = `
+	CREATE TABLE meta (
+		key   TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+
+	CREATE TABLE countries (
+		code                                 TEXT PRIMARY KEY,
+		name                                 TEXT NOT NULL,
+		data_source_name                     TEXT NOT NULL,
+		data_source_url                      TEXT NOT NULL,
+		data_source_description              TEXT NOT NULL,
+		data_source_requires_manual_download INTEGER NOT NULL
+	);
+
+	CREATE TABLE datasets (
+		id                    INTEGER PRIMARY KEY,
+		country_code          TEXT NOT NULL REFERENCES countries(code),
+		source_file_name      TEXT NOT NULL,
+		source_url            TEXT,
+		year_from             INTEGER,
+		year_to               INTEGER,
+		suppression_threshold INTEGER NOT NULL,
+		parse_status          TEXT NOT NULL,
+		parsed_at             TEXT NOT NULL -- RFC 3339, upload time when never parsed
+	);
+
+	CREATE TABLE name_counts (
+		name          TEXT NOT NULL,
+		country_code  TEXT NOT NULL REFERENCES countries(code),
+		year          INTEGER NOT NULL,
+		dataset_id    INTEGER NOT NULL REFERENCES datasets(id),
+		female_count  INTEGER NOT NULL,
+		male_count    INTEGER NOT NULL,
+		unknown_count INTEGER NOT NULL
+	);
+
+	CREATE TABLE births (
+		country_code TEXT NOT NULL REFERENCES countries(code),
+		year         INTEGER NOT NULL,
+		total_births INTEGER NOT NULL,
+		PRIMARY KEY (country_code, year)
+	) WITHOUT ROWID;
+`
+
+// indexes are created after the data is loaded. The NOCASE name index serves
+// LIKE lookups, which fold ASCII case like ILIKE.
+const indexes = `
+	CREATE INDEX idx_name_counts_name ON name_counts(name COLLATE NOCASE);
+	CREATE INDEX idx_name_counts_year ON name_counts(year, country_code);
+	CREATE INDEX idx_datasets_country ON datasets(country_code);
+	ANALYZE;
+`
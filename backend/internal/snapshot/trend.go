@@ -0,0 +1,346 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/supercakecrumb/nomia/internal/db"
+)
+
+// trendFilter selects the name_counts n rows of a trend: the name, matched
+// without case, in the trend range for the selected countries
+const trendFilter = `
+	n.name = :name COLLATE NOCASE
+	AND n.year >= :year_from
+	AND n.year <= :year_to
+	AND (:countries IS NULL OR n.country_code IN (SELECT value FROM json_each(:countries)))
+`
+
+// datasetYears expands the parsed datasets of the selected countries into
+// one row per year they cover inside the trend range
+const datasetYears = `
+	dataset_years(country_code, year, year_to, threshold) AS (
+		SELECT
+			country_code,
+			MAX(year_from, :year_from),
+			MIN(year_to, :year_to),
+			suppression_threshold
+		FROM datasets
+		WHERE parse_status = 'parsed'
+		  AND year_from <= :year_to
+		  AND year_to >= :year_from
+		  AND (:countries IS NULL OR country_code IN (SELECT value FROM json_each(:countries)))
+		UNION ALL
+		SELECT country_code, year + 1, year_to, threshold
+		FROM dataset_years
+		WHERE year < year_to
+	)
+`
+
+// trendArgs returns the named arguments the trend queries read
+func trendArgs(params *db.NameTrendParams) []interface{} {
+	return []interface{}{
+		sql.Named("name", params.Name),
+		sql.Named("year_from", params.YearFrom),
+		sql.Named("year_to", params.YearTo),
+		sql.Named("countries", countriesArg(params.Countries)),
+	}
+}
+
+func (r *Reader) GetNameTrend(ctx context.Context, params *db.NameTrendParams) (*db.NameTrendResponse, error) {
+	args := trendArgs(params)
+
+	// Query 1: Overall summary
+	summaryQuery := `
+		SELECT
+			SUM(n.female_count + n.male_count + n.unknown_count) as total_count,
+			SUM(n.female_count) as female_count,
+			SUM(n.male_count) as male_count,
+			SUM(n.unknown_count) as unknown_count,
+			100.0 * SUM(n.male_count) / NULLIF(SUM(n.female_count + n.male_count), 0) as gender_balance,
+			MIN(n.year) as name_start,
+			MAX(n.year) as name_end,
+			group_concat(DISTINCT n.country_code ORDER BY n.country_code) as countries
+		FROM name_counts n
+		WHERE ` + trendFilter
+
+	var summary db.NameTrendSummary
+	var genderBalance *float64
+	var totalCount, femaleCount, maleCount, unknownCount, nameStart, nameEnd *int
+	var countries *string
+
+	err := r.sqlite.QueryRowContext(ctx, summaryQuery, args...).Scan(
+		&totalCount,
+		&femaleCount,
+		&maleCount,
+		&unknownCount,
+		&genderBalance,
+		&nameStart,
+		&nameEnd,
+		&countries,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("summary query failed: %w", err)
+	}
+
+	// If totalCount is NULL, no data exists for this name
+	summary.Countries = []string{}
+	if totalCount != nil && *totalCount > 0 {
+		summary.TotalCount = *totalCount
+		summary.FemaleCount = *femaleCount
+		summary.MaleCount = *maleCount
+		summary.UnknownCount = *unknownCount
+		summary.HasUnknownData = *unknownCount > 0
+		summary.NameStart = *nameStart
+		summary.NameEnd = *nameEnd
+		summary.Countries = strings.Split(*countries, ",")
+	}
+	if genderBalance != nil {
+		summary.GenderBalance = *genderBalance
+	}
+
+	// Query 2: Time series
+	timeSeriesQuery := `
+		SELECT
+			n.year,
+			SUM(n.female_count + n.male_count + n.unknown_count) as total_count,
+			SUM(n.female_count) as female_count,
+			SUM(n.male_count) as male_count,
+			SUM(n.unknown_count) as unknown_count,
+			100.0 * SUM(n.male_count) / NULLIF(SUM(n.female_count + n.male_count), 0) as gender_balance
+		FROM name_counts n
+		WHERE ` + trendFilter + `
+		GROUP BY n.year
+		ORDER BY n.year
+	`
+
+	rows, err := r.sqlite.QueryContext(ctx, timeSeriesQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("time series query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var timeSeries []db.TimeSeriesPoint
+	for rows.Next() {
+		var ts db.TimeSeriesPoint
+		var gb *float64
+		if err := rows.Scan(&ts.Year, &ts.TotalCount, &ts.FemaleCount, &ts.MaleCount, &ts.UnknownCount, &gb); err != nil {
+			return nil, fmt.Errorf("time series scan failed: %w", err)
+		}
+		if gb != nil {
+			ts.GenderBalance = *gb
+		}
+		ts.GenderBalanceLow, ts.GenderBalanceHigh, _ = db.GenderBalanceInterval(ts.MaleCount, ts.FemaleCount)
+		timeSeries = append(timeSeries, ts)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("time series rows failed: %w", err)
+	}
+	rows.Close()
+
+	// Fill every requested year, telling apart years with no births from
+	// years no dataset covers
+	covered, err := r.coveredYears(ctx, args)
+	if err != nil {
+		return nil, fmt.Errorf("coverage query failed: %w", err)
+	}
+	timeSeries = db.DensifyTimeSeries(timeSeries, params.YearFrom, params.YearTo, covered)
+
+	// Flag years where suppressed counts may be hiding births
+	suppressed, err := r.suppressedCounts(ctx, args)
+	if err != nil {
+		return nil, fmt.Errorf("suppression query failed: %w", err)
+	}
+	db.ApplySuppression(timeSeries, &summary, suppressed, params.Suppression)
+	drift := db.ComputeDriftSummary(timeSeries)
+
+	// Project the yearly share of births forward
+	var forecastModel *db.ForecastModel
+	var forecast []db.ForecastPoint
+	if params.ForecastYears > 0 {
+		births, err := r.birthTotals(ctx, args)
+		if err != nil {
+			return nil, fmt.Errorf("births query failed: %w", err)
+		}
+		years, shares := db.ForecastInput(timeSeries, births)
+		forecastModel, forecast = db.ForecastDampedTrend(years, shares, params.ForecastYears)
+	}
+
+	// A snapshot holds no per-year ranks, so points carry none
+	timeSeries = db.BucketTimeSeries(timeSeries, params.Interval, params.Suppression)
+
+	// Query 3: By country
+	byCountryQuery := `
+		SELECT
+			c.code as country_code,
+			c.name as country_name,
+			SUM(n.female_count + n.male_count + n.unknown_count) as total_count,
+			SUM(n.female_count) as female_count,
+			SUM(n.male_count) as male_count,
+			SUM(n.unknown_count) as unknown_count,
+			100.0 * SUM(n.male_count) / NULLIF(SUM(n.female_count + n.male_count), 0) as gender_balance
+		FROM name_counts n
+		JOIN countries c ON n.country_code = c.code
+		WHERE ` + trendFilter + `
+		GROUP BY c.code, c.name
+		ORDER BY total_count DESC, c.code
+	`
+
+	rows, err = r.sqlite.QueryContext(ctx, byCountryQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("by country query failed: %w", err)
+	}
+	defer rows.Close()
+
+	byCountry := []db.CountryBreakdown{}
+	for rows.Next() {
+		var cb db.CountryBreakdown
+		var gb *float64
+		err := rows.Scan(&cb.CountryCode, &cb.CountryName, &cb.TotalCount,
+			&cb.FemaleCount, &cb.MaleCount, &cb.UnknownCount, &gb)
+		if err != nil {
+			return nil, fmt.Errorf("by country scan failed: %w", err)
+		}
+		if gb != nil {
+			cb.GenderBalance = *gb
+		}
+		byCountry = append(byCountry, cb)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("by country rows failed: %w", err)
+	}
+
+	return &db.NameTrendResponse{
+		Name: params.Name,
+		Meta: map[string]int{
+			"db_start": r.yearRange.MinYear,
+			"db_end":   r.yearRange.MaxYear,
+		},
+		Summary:       summary,
+		Drift:         drift,
+		TimeSeries:    timeSeries,
+		ByCountry:     byCountry,
+		Forecast:      forecast,
+		ForecastModel: forecastModel,
+	}, nil
+}
+
+// coveredYears returns the years in the trend range that at least one parsed
+// dataset of the selected countries covers
+func (r *Reader) coveredYears(ctx context.Context, args []interface{}) (map[int]bool, error) {
+	query := `WITH RECURSIVE ` + datasetYears + `
+		SELECT DISTINCT year FROM dataset_years
+	`
+
+	rows, err := r.sqlite.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	covered := make(map[int]bool)
+	for rows.Next() {
+		var year int
+		if err := rows.Scan(&year); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		covered[year] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows failed: %w", err)
+	}
+
+	return covered, nil
+}
+
+// suppressedCounts returns, per year, how many female and male births of the
+// name may be missing because a covering dataset suppresses small counts.
+// A sex counts as suppressed in a country-year when the dataset covers that
+// year but has no births of the name and sex.
+func (r *Reader) suppressedCounts(ctx context.Context, args []interface{}) (map[int]db.SuppressedCounts, error) {
+	query := `
+		WITH RECURSIVE ` + datasetYears + `,
+		coverage AS (
+			SELECT country_code, year, MAX(threshold) as threshold
+			FROM dataset_years
+			WHERE threshold > 0
+			GROUP BY country_code, year
+		),
+		present AS (
+			SELECT
+				n.country_code,
+				n.year,
+				MAX(n.female_count > 0) as has_female,
+				MAX(n.male_count > 0) as has_male
+			FROM name_counts n
+			WHERE ` + trendFilter + `
+			GROUP BY n.country_code, n.year
+		)
+		SELECT
+			cv.year,
+			SUM(CASE WHEN COALESCE(p.has_female, 0) THEN 0 ELSE cv.threshold - 1 END) as female_max,
+			SUM(CASE WHEN COALESCE(p.has_male, 0) THEN 0 ELSE cv.threshold - 1 END) as male_max
+		FROM coverage cv
+		LEFT JOIN present p ON p.country_code = cv.country_code AND p.year = cv.year
+		GROUP BY cv.year
+		ORDER BY cv.year
+	`
+
+	rows, err := r.sqlite.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int]db.SuppressedCounts)
+	for rows.Next() {
+		var year int
+		var sc db.SuppressedCounts
+		if err := rows.Scan(&year, &sc.FemaleMax, &sc.MaleMax); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		if sc.FemaleMax > 0 || sc.MaleMax > 0 {
+			result[year] = sc
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows failed: %w", err)
+	}
+
+	return result, nil
+}
+
+// birthTotals returns all recorded births per year for the selected countries
+func (r *Reader) birthTotals(ctx context.Context, args []interface{}) (map[int]int64, error) {
+	query := `
+		SELECT year, SUM(total_births)
+		FROM births
+		WHERE year >= :year_from
+		  AND year <= :year_to
+		  AND (:countries IS NULL OR country_code IN (SELECT value FROM json_each(:countries)))
+		GROUP BY year
+	`
+
+	rows, err := r.sqlite.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	births := make(map[int]int64)
+	for rows.Next() {
+		var year int
+		var total int64
+		if err := rows.Scan(&year, &total); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		births[year] = total
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows failed: %w", err)
+	}
+
+	return births, nil
+}
@@ -0,0 +1,247 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// Summary counts what a snapshot holds
+type Summary struct {
+	Countries   int
+	Datasets    int
+	NameCounts  int64
+	Births      int
+	DataVersion int64
+}
+
+// Write copies the countries, datasets, aggregated name counts and births
+// from PostgreSQL into a new SQLite file at path. The file is built next to
+// path and renamed into place, so an existing snapshot is only replaced by
+// a complete one.
+func Write(ctx context.Context, pool *pgxpool.Pool, path string) (*Summary, error) {
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	defer os.Remove(tmp)
+
+	summary, err := write(ctx, pool, tmp)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, fmt.Errorf("failed to move snapshot into place: %w", err)
+	}
+
+	return summary, nil
+}
+
+func write(ctx context.Context, pool *pgxpool.Pool, path string) (*Summary, error) {
+	lite, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer lite.Close()
+	lite.SetMaxOpenConns(1) // pragmas are per connection
+
+	// The file is discarded on failure, so skip the journal
+	if _, err := lite.ExecContext(ctx, `PRAGMA journal_mode = OFF; PRAGMA synchronous = OFF`); err != nil {
+		return nil, fmt.Errorf("failed to configure snapshot: %w", err)
+	}
+	if _, err := lite.ExecContext(ctx, schema); err != nil {
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	// Read everything in one repeatable-read transaction so the snapshot is
+	// consistent with the data version it records
+	src, err := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin read: %w", err)
+	}
+	defer src.Rollback(ctx)
+
+	tx, err := lite.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin write: %w", err)
+	}
+	defer tx.Rollback()
+
+	summary := &Summary{}
+	if err := src.QueryRow(ctx, `SELECT version FROM data_version WHERE id = 1`).Scan(&summary.DataVersion); err != nil {
+		return nil, fmt.Errorf("failed to read data version: %w", err)
+	}
+
+	// Countries
+	rows, err := src.Query(ctx, `
+		SELECT
+			code,
+			name,
+			data_source_name,
+			data_source_url,
+			COALESCE(data_source_description, ''),
+			data_source_requires_manual_download
+		FROM countries
+		ORDER BY code
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("countries query failed: %w", err)
+	}
+	for rows.Next() {
+		var code, name, sourceName, sourceURL, description string
+		var manual bool
+		if err := rows.Scan(&code, &name, &sourceName, &sourceURL, &description, &manual); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("countries scan failed: %w", err)
+		}
+		_, err := tx.ExecContext(ctx, `INSERT INTO countries VALUES (?, ?, ?, ?, ?, ?)`,
+			code, name, sourceName, sourceURL, description, manual)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("countries insert failed: %w", err)
+		}
+		summary.Countries++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("countries rows failed: %w", err)
+	}
+
+	// Datasets
+	rows, err = src.Query(ctx, `
+		SELECT
+			d.id,
+			c.code,
+			d.source_file_name,
+			d.source_url,
+			d.year_from,
+			d.year_to,
+			d.suppression_threshold,
+			d.parse_status,
+			COALESCE(d.parsed_at, d.uploaded_at)
+		FROM name_datasets d
+		JOIN countries c ON d.country_id = c.id
+		ORDER BY d.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("datasets query failed: %w", err)
+	}
+	for rows.Next() {
+		var id, threshold int
+		var code, fileName, status string
+		var sourceURL *string
+		var yearFrom, yearTo *int
+		var parsedAt time.Time
+		if err := rows.Scan(&id, &code, &fileName, &sourceURL, &yearFrom, &yearTo, &threshold, &status, &parsedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("datasets scan failed: %w", err)
+		}
+		_, err := tx.ExecContext(ctx, `INSERT INTO datasets VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, code, fileName, sourceURL, yearFrom, yearTo, threshold, status, parsedAt.UTC().Format(time.RFC3339))
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("datasets insert failed: %w", err)
+		}
+		summary.Datasets++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("datasets rows failed: %w", err)
+	}
+
+	// Name counts, pivoted by gender
+	rows, err = src.Query(ctx, `
+		SELECT
+			n.name,
+			c.code,
+			n.year,
+			n.dataset_id,
+			SUM(CASE WHEN n.gender = 'F' THEN n.count ELSE 0 END),
+			SUM(CASE WHEN n.gender = 'M' THEN n.count ELSE 0 END),
+			SUM(CASE WHEN n.gender = 'U' THEN n.count ELSE 0 END)
+		FROM names n
+		JOIN countries c ON n.country_id = c.id
+		GROUP BY n.name, c.code, n.year, n.dataset_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("names query failed: %w", err)
+	}
+	insert, err := tx.PrepareContext(ctx, `INSERT INTO name_counts VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("names insert failed: %w", err)
+	}
+	for rows.Next() {
+		var name, code string
+		var year, datasetID, female, male, unknown int
+		if err := rows.Scan(&name, &code, &year, &datasetID, &female, &male, &unknown); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("names scan failed: %w", err)
+		}
+		if _, err := insert.ExecContext(ctx, name, code, year, datasetID, female, male, unknown); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("names insert failed: %w", err)
+		}
+		summary.NameCounts++
+	}
+	rows.Close()
+	insert.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("names rows failed: %w", err)
+	}
+
+	// Births
+	rows, err = src.Query(ctx, `
+		SELECT c.code, b.year, b.total_births
+		FROM country_year_births b
+		JOIN countries c ON b.country_id = c.id
+		ORDER BY c.code, b.year
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("births query failed: %w", err)
+	}
+	for rows.Next() {
+		var code string
+		var year int
+		var total int64
+		if err := rows.Scan(&code, &year, &total); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("births scan failed: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO births VALUES (?, ?, ?)`, code, year, total); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("births insert failed: %w", err)
+		}
+		summary.Births++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("births rows failed: %w", err)
+	}
+
+	meta := map[string]string{
+		"format":         Format,
+		"format_version": FormatVersion,
+		"data_version":   strconv.FormatInt(summary.DataVersion, 10),
+		"created_at":     time.Now().UTC().Format(time.RFC3339),
+	}
+	for key, value := range meta {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO meta VALUES (?, ?)`, key, value); err != nil {
+			return nil, fmt.Errorf("meta insert failed: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit snapshot: %w", err)
+	}
+	if _, err := lite.ExecContext(ctx, indexes); err != nil {
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,108 @@
+// Package ssa reads the US Social Security Administration baby names files:
+// one yobYYYY.txt file per year with a name,gender,count line per name and
+// sex.
+package ssa
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SuppressionThreshold is the minimum count SSA publishes for a
+// name/sex/year; anything below it is omitted from the files
+const SuppressionThreshold = 5
+
+var fileName = regexp.MustCompile(`^yob(\d{4})\.txt$`)
+
+// Record is one line of an SSA file: the births of a name and sex in the
+// file's year
+type Record struct {
+	Name   string
+	Gender string // "F" or "M"
+	Count  int
+}
+
+// IsFileName reports whether name is an SSA file name (yob2023.txt)
+func IsFileName(name string) bool {
+	return fileName.MatchString(name)
+}
+
+// FileYear extracts the year from an SSA file name (yob2023.txt)
+func FileYear(name string) (int, error) {
+	matches := fileName.FindStringSubmatch(name)
+	if matches == nil {
+		return 0, fmt.Errorf("filename does not match expected format 'yobYYYY.txt'")
+	}
+
+	year, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid year in filename: %v", err)
+	}
+
+	return year, nil
+}
+
+// ParseFile parses the SSA file at path
+func ParseFile(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse reads SSA records (name,gender,count) from r, rejecting lines with
+// another gender than F or M or a count that is not positive
+func Parse(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		// Parse CSV: name,gender,count
+		parts := strings.Split(line, ",")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid format at line %d: expected 3 fields, got %d", lineNum, len(parts))
+		}
+
+		name := strings.TrimSpace(parts[0])
+		gender := strings.TrimSpace(parts[1])
+		countStr := strings.TrimSpace(parts[2])
+
+		// Validate gender
+		if gender != "F" && gender != "M" {
+			return nil, fmt.Errorf("invalid gender at line %d: %s (expected F or M)", lineNum, gender)
+		}
+
+		// Parse count
+		count, err := strconv.Atoi(countStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid count at line %d: %v", lineNum, err)
+		}
+
+		if count <= 0 {
+			return nil, fmt.Errorf("invalid count at line %d: must be positive", lineNum)
+		}
+
+		records = append(records, Record{Name: name, Gender: gender, Count: count})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
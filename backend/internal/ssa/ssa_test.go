@@ -0,0 +1,52 @@
+package ssa
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	records, err := Parse(strings.NewReader("Emma,F,900\n\n Alex , M , 300 \n"))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error = %v", err)
+	}
+	want := []Record{
+		{Name: "Emma", Gender: "F", Count: 900},
+		{Name: "Alex", Gender: "M", Count: 300},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("Parse() = %+v, want %+v", records, want)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{"missing field", "Emma,F\n", "invalid format at line 1"},
+		{"bad gender", "Emma,F,5\nEmma,X,5\n", "invalid gender at line 2"},
+		{"bad count", "Emma,F,five\n", "invalid count at line 1"},
+		{"zero count", "Emma,F,0\n", "invalid count at line 1: must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Parse() error = %v, want error containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestFileYear(t *testing.T) {
+	if year, err := FileYear("yob2023.txt"); err != nil || year != 2023 {
+		t.Errorf("FileYear(yob2023.txt) = %d, %v, want 2023", year, err)
+	}
+	for _, name := range []string{"names2023.txt", "yob23.txt", "yob2023.txt.bak"} {
+		if _, err := FileYear(name); err == nil {
+			t.Errorf("FileYear(%s) expected error", name)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+-- Nomia - Births Totals Migration
+-- Version: 004
+-- Description: Per-country, per-year births totals used to normalize popularity
+-- Date: 2026-10-18
+
+-- ============================================================================
+-- Table: country_year_births
+-- Purpose: Denominator for country-normalized popularity (per-100k births)
+-- ============================================================================
+
+CREATE TABLE country_year_births (
+    country_id INTEGER NOT NULL REFERENCES countries(id) ON DELETE RESTRICT,
+    year INTEGER NOT NULL CHECK (year >= 1800 AND year <= 2100),
+    total_births BIGINT NOT NULL CHECK (total_births > 0),
+    female_births BIGINT NOT NULL DEFAULT 0,
+    male_births BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (country_id, year)
+);
+
+COMMENT ON TABLE country_year_births IS 'Births recorded per country and year, refreshed by the import tool';
+COMMENT ON COLUMN country_year_births.total_births IS 'Sum of all name counts imported for the country and year (names below the publication threshold are not included)';
+
+-- ============================================================================
+-- Backfill from already imported data
+-- ============================================================================
+
+INSERT INTO country_year_births (country_id, year, total_births, female_births, male_births)
+SELECT
+    country_id,
+    year,
+    SUM(count),
+    SUM(CASE WHEN gender = 'F' THEN count ELSE 0 END),
+    SUM(CASE WHEN gender = 'M' THEN count ELSE 0 END)
+FROM names
+GROUP BY country_id, year
+ON CONFLICT (country_id, year) DO NOTHING;
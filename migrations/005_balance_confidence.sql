@@ -0,0 +1,42 @@
+-- Nomia - Gender Balance Confidence Migration
+-- Version: 005
+-- Description: Wilson score interval helpers for gender balance filtering
+-- Date: 2026-10-18
+
+-- ============================================================================
+-- Functions: wilson_lower / wilson_upper
+-- Purpose: Bounds of the Wilson score interval for a binomial proportion
+-- (successes out of trials). Returns NULL when there are no trials.
+-- The Go implementation lives in backend/internal/db/confidence.go.
+-- ============================================================================
+
+CREATE OR REPLACE FUNCTION wilson_lower(successes NUMERIC, trials NUMERIC, z FLOAT8 DEFAULT 1.96)
+RETURNS FLOAT8
+LANGUAGE sql
+IMMUTABLE
+AS $$
+    SELECT CASE WHEN trials > 0 THEN
+        GREATEST(0.0,
+            ((successes / trials)::float8 + z * z / (2 * trials::float8)
+             - z * sqrt((successes / trials)::float8 * (1 - (successes / trials)::float8) / trials::float8
+                        + z * z / (4 * trials::float8 * trials::float8)))
+            / (1 + z * z / trials::float8))
+    END
+$$;
+
+CREATE OR REPLACE FUNCTION wilson_upper(successes NUMERIC, trials NUMERIC, z FLOAT8 DEFAULT 1.96)
+RETURNS FLOAT8
+LANGUAGE sql
+IMMUTABLE
+AS $$
+    SELECT CASE WHEN trials > 0 THEN
+        LEAST(1.0,
+            ((successes / trials)::float8 + z * z / (2 * trials::float8)
+             + z * sqrt((successes / trials)::float8 * (1 - (successes / trials)::float8) / trials::float8
+                        + z * z / (4 * trials::float8 * trials::float8)))
+            / (1 + z * z / trials::float8))
+    END
+$$;
+
+COMMENT ON FUNCTION wilson_lower(NUMERIC, NUMERIC, FLOAT8) IS 'Lower bound of the Wilson score interval (default 95%)';
+COMMENT ON FUNCTION wilson_upper(NUMERIC, NUMERIC, FLOAT8) IS 'Upper bound of the Wilson score interval (default 95%)';
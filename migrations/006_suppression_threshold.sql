@@ -0,0 +1,14 @@
+-- Nomia - Suppression Threshold Migration
+-- Version: 006
+-- Description: Record per-dataset publication thresholds (e.g. SSA omits
+--              any name/sex/year with fewer than 5 births)
+-- Date: 2026-10-18
+
+ALTER TABLE name_datasets
+    ADD COLUMN suppression_threshold INTEGER NOT NULL DEFAULT 0
+    CHECK (suppression_threshold >= 0);
+
+COMMENT ON COLUMN name_datasets.suppression_threshold IS 'Counts below this value are omitted from the source file (0 = no suppression)';
+
+-- SSA files suppress name/sex/year combinations with fewer than 5 births
+UPDATE name_datasets SET suppression_threshold = 5 WHERE file_type = 'SSA-TXT';
@@ -0,0 +1,94 @@
+-- Nomia - Per-Year Name Ranks Migration
+-- Version: 007
+-- Description: Precomputed per-year ranks and shares of births for the
+--              name trend time series
+-- Date: 2026-10-18
+
+-- ============================================================================
+-- Table: name_year_ranks
+-- Purpose: Rank of every name in each year, overall and within sex, per
+-- country and pooled over all countries
+-- ============================================================================
+
+CREATE TABLE name_year_ranks (
+    scope VARCHAR(10) NOT NULL,
+    year INTEGER NOT NULL CHECK (year >= 1800 AND year <= 2100),
+    name VARCHAR(255) NOT NULL,
+    total_count BIGINT NOT NULL,
+    female_count BIGINT NOT NULL,
+    male_count BIGINT NOT NULL,
+    overall_rank INTEGER NOT NULL,
+    female_rank INTEGER,
+    male_rank INTEGER,
+    share_of_births FLOAT8 NOT NULL,
+    PRIMARY KEY (scope, year, name)
+);
+
+CREATE INDEX idx_name_year_ranks_name ON name_year_ranks(scope, name, year);
+
+COMMENT ON TABLE name_year_ranks IS 'Per-year name ranks, refreshed by the import tool via refresh_name_year_ranks()';
+COMMENT ON COLUMN name_year_ranks.scope IS 'Country code, or ''*'' for all countries pooled';
+COMMENT ON COLUMN name_year_ranks.female_rank IS 'Rank among names with female births that year (NULL when the name has none)';
+COMMENT ON COLUMN name_year_ranks.share_of_births IS 'total_count / all births recorded in the scope and year (0-1)';
+
+-- ============================================================================
+-- Function: refresh_name_year_ranks
+-- Purpose: Recompute all scopes for one year. Ties are broken by name, the
+-- same as the rank column of /api/names.
+-- ============================================================================
+
+CREATE OR REPLACE FUNCTION refresh_name_year_ranks(p_year INTEGER)
+RETURNS VOID
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    DELETE FROM name_year_ranks WHERE year = p_year;
+
+    INSERT INTO name_year_ranks (
+        scope, year, name, total_count, female_count, male_count,
+        overall_rank, female_rank, male_rank, share_of_births
+    )
+    WITH
+    per_country AS (
+        SELECT
+            c.code as scope,
+            n.name,
+            SUM(n.count) as total_count,
+            SUM(CASE WHEN n.gender = 'F' THEN n.count ELSE 0 END) as female_count,
+            SUM(CASE WHEN n.gender = 'M' THEN n.count ELSE 0 END) as male_count
+        FROM names n
+        JOIN countries c ON n.country_id = c.id
+        WHERE n.year = p_year
+        GROUP BY c.code, n.name
+    ),
+    scoped AS (
+        SELECT * FROM per_country
+        UNION ALL
+        SELECT '*', name, SUM(total_count), SUM(female_count), SUM(male_count)
+        FROM per_country
+        GROUP BY name
+    )
+    SELECT
+        scope,
+        p_year,
+        name,
+        total_count,
+        female_count,
+        male_count,
+        ROW_NUMBER() OVER (PARTITION BY scope ORDER BY total_count DESC, name ASC),
+        CASE WHEN female_count > 0 THEN
+            ROW_NUMBER() OVER (PARTITION BY scope, female_count > 0 ORDER BY female_count DESC, name ASC)
+        END,
+        CASE WHEN male_count > 0 THEN
+            ROW_NUMBER() OVER (PARTITION BY scope, male_count > 0 ORDER BY male_count DESC, name ASC)
+        END,
+        COALESCE(total_count::float8 / NULLIF(SUM(total_count) OVER (PARTITION BY scope), 0), 0)
+    FROM scoped;
+END;
+$$;
+
+-- ============================================================================
+-- Backfill from already imported data
+-- ============================================================================
+
+SELECT refresh_name_year_ranks(year) FROM (SELECT DISTINCT year FROM names) y;
@@ -0,0 +1,84 @@
+-- Nomia - Name Trajectories Migration
+-- Version: 008
+-- Description: Precomputed per-decade popularity and gender balance vectors
+--              for trajectory similarity search
+-- Date: 2026-10-18
+
+-- ============================================================================
+-- Table: name_trajectories
+-- Purpose: One row per name with decade vectors over all countries pooled.
+-- All rows share the same decade axis starting at first_decade.
+-- ============================================================================
+
+CREATE TABLE name_trajectories (
+    name VARCHAR(255) PRIMARY KEY,
+    first_decade INTEGER NOT NULL,
+    total_count BIGINT NOT NULL,
+    share_vector FLOAT8[] NOT NULL,
+    balance_vector FLOAT8[] NOT NULL
+);
+
+CREATE INDEX idx_name_trajectories_total ON name_trajectories(total_count);
+
+COMMENT ON TABLE name_trajectories IS 'Decade trajectory vectors, rebuilt by the import tool via refresh_name_trajectories()';
+COMMENT ON COLUMN name_trajectories.share_vector IS 'Summed yearly share of births per decade, divided by the name''s peak decade (0-1)';
+COMMENT ON COLUMN name_trajectories.balance_vector IS 'Gender balance per decade (0-100), NaN when the decade has no binary data';
+
+-- ============================================================================
+-- Function: refresh_name_trajectories
+-- Purpose: Rebuild all vectors from the pooled scope of name_year_ranks
+-- ============================================================================
+
+CREATE OR REPLACE FUNCTION refresh_name_trajectories()
+RETURNS VOID
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    DELETE FROM name_trajectories;
+
+    INSERT INTO name_trajectories (name, first_decade, total_count, share_vector, balance_vector)
+    WITH
+    bounds AS (
+        SELECT MIN(year) / 10 * 10 as first_decade, MAX(year) / 10 * 10 as last_decade
+        FROM name_year_ranks
+        WHERE scope = '*'
+    ),
+    decades AS (
+        SELECT generate_series(first_decade, last_decade, 10) as decade FROM bounds
+    ),
+    per_decade AS (
+        SELECT
+            name,
+            year / 10 * 10 as decade,
+            SUM(share_of_births) as share_sum,
+            SUM(female_count) as female_count,
+            SUM(male_count) as male_count,
+            SUM(total_count) as total_count
+        FROM name_year_ranks
+        WHERE scope = '*'
+        GROUP BY name, year / 10 * 10
+    ),
+    totals AS (
+        SELECT name, SUM(total_count) as total_count, MAX(share_sum) as peak_share
+        FROM per_decade
+        GROUP BY name
+    )
+    SELECT
+        t.name,
+        b.first_decade,
+        t.total_count,
+        ARRAY_AGG(COALESCE(pd.share_sum / NULLIF(t.peak_share, 0), 0) ORDER BY d.decade),
+        ARRAY_AGG(COALESCE(100.0 * pd.male_count::float8 / NULLIF(pd.female_count + pd.male_count, 0), 'NaN'::float8) ORDER BY d.decade)
+    FROM totals t
+    CROSS JOIN bounds b
+    CROSS JOIN decades d
+    LEFT JOIN per_decade pd ON pd.name = t.name AND pd.decade = d.decade
+    GROUP BY t.name, b.first_decade, t.total_count;
+END;
+$$;
+
+-- ============================================================================
+-- Backfill from already imported data
+-- ============================================================================
+
+SELECT refresh_name_trajectories();
@@ -0,0 +1,37 @@
+-- Nomia - Survival Tables Migration
+-- Version: 009
+-- Description: Per-country period life tables used to estimate living
+--              bearers of a name
+-- Date: 2026-10-18
+
+-- ============================================================================
+-- Table: life_tables
+-- Purpose: One loaded life table per country (replaced on re-import)
+-- ============================================================================
+
+CREATE TABLE life_tables (
+    country_id INTEGER PRIMARY KEY REFERENCES countries(id) ON DELETE RESTRICT,
+    format VARCHAR(20) NOT NULL,
+    period_year INTEGER NOT NULL CHECK (period_year >= 1800 AND period_year <= 2100),
+    source_file_name VARCHAR(255) NOT NULL,
+    loaded_at TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+COMMENT ON TABLE life_tables IS 'Life table loaded for each country by the import tool (-life-table)';
+COMMENT ON COLUMN life_tables.format IS 'Source format, e.g. SSA-PERIOD';
+COMMENT ON COLUMN life_tables.period_year IS 'Calendar year the period table describes';
+
+-- ============================================================================
+-- Table: survival_rates
+-- Purpose: Survivors to each exact age out of 100,000 births (l_x)
+-- ============================================================================
+
+CREATE TABLE survival_rates (
+    country_id INTEGER NOT NULL REFERENCES life_tables(country_id) ON DELETE CASCADE,
+    sex CHAR(1) NOT NULL CHECK (sex IN ('F', 'M')),
+    age INTEGER NOT NULL CHECK (age >= 0 AND age <= 150),
+    survivors FLOAT8 NOT NULL CHECK (survivors >= 0 AND survivors <= 100000),
+    PRIMARY KEY (country_id, sex, age)
+);
+
+COMMENT ON TABLE survival_rates IS 'Life table l_x column per country and sex (radix 100,000)';
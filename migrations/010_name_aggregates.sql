@@ -0,0 +1,226 @@
+-- Nomia - Name Aggregates Migration
+-- Version: 010
+-- Description: Precomputed per-(name, country, year) rollups and whole-range
+--              name_stats, refreshed per imported or removed country-year
+-- Date: 2026-10-18
+
+-- ============================================================================
+-- Table: name_year_rollups
+-- Purpose: One row per name, country and year with counts by sex, so the
+-- names list pipeline does not have to group raw per-gender rows
+-- ============================================================================
+
+CREATE TABLE name_year_rollups (
+    country_id INTEGER NOT NULL REFERENCES countries(id) ON DELETE RESTRICT,
+    year INTEGER NOT NULL CHECK (year >= 1800 AND year <= 2100),
+    name VARCHAR(255) NOT NULL,
+    total_count BIGINT NOT NULL,
+    female_count BIGINT NOT NULL,
+    male_count BIGINT NOT NULL,
+    unknown_count BIGINT NOT NULL,
+    PRIMARY KEY (country_id, year, name)
+);
+
+CREATE INDEX idx_rollups_name_trgm ON name_year_rollups USING GIN (name gin_trgm_ops);
+CREATE INDEX idx_rollups_name ON name_year_rollups(name);
+
+COMMENT ON TABLE name_year_rollups IS 'Per-name, country and year counts rolled up from names by refresh_name_aggregates()';
+
+-- ============================================================================
+-- Table: name_stats
+-- Purpose: Whole-range aggregates over all countries and years. The metric
+-- definitions match the GetNamesList pipeline, which reads this table
+-- instead of aggregating when a request covers the full range.
+-- ============================================================================
+
+CREATE TABLE name_stats (
+    name VARCHAR(255) PRIMARY KEY,
+    total_count BIGINT NOT NULL,
+    female_count BIGINT NOT NULL,
+    male_count BIGINT NOT NULL,
+    unknown_count BIGINT NOT NULL,
+    gender_balance FLOAT8,
+    earliest_year INTEGER NOT NULL,
+    latest_year INTEGER NOT NULL,
+    country_codes TEXT[] NOT NULL,
+    drift FLOAT8,
+    early_balance FLOAT8,
+    late_balance FLOAT8,
+    balance_volatility FLOAT8,
+    peak_year INTEGER,
+    peak_share FLOAT8,
+    era_start INTEGER,
+    era_end INTEGER,
+    updated_at TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX idx_name_stats_name_trgm ON name_stats USING GIN (name gin_trgm_ops);
+
+COMMENT ON TABLE name_stats IS 'Whole-range per-name aggregates over all countries, refreshed by refresh_name_stats()';
+COMMENT ON COLUMN name_stats.gender_balance IS 'Male share of binary births (0-100), NULL without binary data';
+
+-- ============================================================================
+-- Function: refresh_name_stats
+-- Purpose: Recompute name_stats for the given names (NULL = all names)
+-- ============================================================================
+
+CREATE OR REPLACE FUNCTION refresh_name_stats(p_names TEXT[])
+RETURNS VOID
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    DELETE FROM name_stats WHERE p_names IS NULL OR name = ANY(p_names);
+
+    INSERT INTO name_stats (
+        name, total_count, female_count, male_count, unknown_count, gender_balance,
+        earliest_year, latest_year, country_codes,
+        drift, early_balance, late_balance, balance_volatility,
+        peak_year, peak_share, era_start, era_end
+    )
+    WITH
+    rollups AS (
+        SELECT r.*, c.code as country_code
+        FROM name_year_rollups r
+        JOIN countries c ON r.country_id = c.id
+        WHERE p_names IS NULL OR r.name = ANY(p_names)
+    ),
+    year_births AS (
+        SELECT year, SUM(total_births) as births
+        FROM country_year_births
+        GROUP BY year
+    ),
+    yearly AS (
+        SELECT
+            name,
+            year,
+            SUM(total_count) as total_count,
+            SUM(male_count) as male_count,
+            SUM(female_count + male_count) as binary_count,
+            100.0 * SUM(male_count)::float / NULLIF(SUM(female_count + male_count), 0) as gender_balance,
+            MIN(year) OVER (PARTITION BY name) as first_year,
+            MAX(year) OVER (PARTITION BY name) as last_year
+        FROM rollups
+        GROUP BY name, year
+    ),
+    yearly_share AS (
+        SELECT
+            y.*,
+            y.total_count::float / NULLIF(yb.births, 0) as share,
+            SUM(y.total_count) OVER (PARTITION BY y.name ORDER BY y.year) as cumulative_count,
+            SUM(y.total_count) OVER (PARTITION BY y.name) as name_total
+        FROM yearly y
+        LEFT JOIN year_births yb ON yb.year = y.year
+    ),
+    per_year AS (
+        SELECT
+            name,
+            10 * regr_slope(gender_balance, year) as drift,
+            100.0 * (SUM(male_count) FILTER (WHERE year <= first_year + 9))::float /
+                NULLIF(SUM(binary_count) FILTER (WHERE year <= first_year + 9), 0) as early_balance,
+            100.0 * (SUM(male_count) FILTER (WHERE year >= last_year - 9))::float /
+                NULLIF(SUM(binary_count) FILTER (WHERE year >= last_year - 9), 0) as late_balance,
+            CASE WHEN COUNT(gender_balance) >= 2 THEN stddev_pop(gender_balance) END as balance_volatility,
+            (ARRAY_AGG(year ORDER BY share DESC NULLS LAST, total_count DESC, year ASC))[1] as peak_year,
+            MAX(share) as peak_share,
+            MIN(year) FILTER (WHERE cumulative_count >= 0.1 * name_total) as era_start,
+            MIN(year) FILTER (WHERE cumulative_count >= 0.9 * name_total) as era_end
+        FROM yearly_share
+        GROUP BY name
+    ),
+    totals AS (
+        SELECT
+            name,
+            SUM(total_count) as total_count,
+            SUM(female_count) as female_count,
+            SUM(male_count) as male_count,
+            SUM(unknown_count) as unknown_count,
+            MIN(year) as earliest_year,
+            MAX(year) as latest_year,
+            ARRAY_AGG(DISTINCT country_code ORDER BY country_code) as country_codes
+        FROM rollups
+        GROUP BY name
+    )
+    SELECT
+        t.name,
+        t.total_count,
+        t.female_count,
+        t.male_count,
+        t.unknown_count,
+        100.0 * t.male_count::float / NULLIF(t.female_count + t.male_count, 0),
+        t.earliest_year,
+        t.latest_year,
+        t.country_codes,
+        p.drift,
+        p.early_balance,
+        p.late_balance,
+        p.balance_volatility,
+        p.peak_year,
+        p.peak_share,
+        p.era_start,
+        p.era_end
+    FROM totals t
+    JOIN per_year p ON p.name = t.name;
+END;
+$$;
+
+-- ============================================================================
+-- Function: refresh_name_aggregates
+-- Purpose: Rebuild the rollups of one country-year from names and refresh
+-- name_stats for every name present in that year. Call it after importing
+-- or removing a dataset; pooled per-year shares change for all of them.
+-- ============================================================================
+
+CREATE OR REPLACE FUNCTION refresh_name_aggregates(p_country_id INTEGER, p_year INTEGER)
+RETURNS VOID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    affected TEXT[];
+BEGIN
+    DELETE FROM name_year_rollups WHERE country_id = p_country_id AND year = p_year;
+
+    INSERT INTO name_year_rollups (country_id, year, name, total_count, female_count, male_count, unknown_count)
+    SELECT
+        country_id,
+        year,
+        name,
+        SUM(count),
+        SUM(CASE WHEN gender = 'F' THEN count ELSE 0 END),
+        SUM(CASE WHEN gender = 'M' THEN count ELSE 0 END),
+        SUM(CASE WHEN gender = 'U' THEN count ELSE 0 END)
+    FROM names
+    WHERE country_id = p_country_id AND year = p_year
+    GROUP BY country_id, year, name;
+
+    -- Names that just lost every row of the year still need their stats
+    -- recomputed (or dropped), so include the ones name_stats knows about
+    SELECT ARRAY_AGG(DISTINCT name) INTO affected
+    FROM (
+        SELECT name FROM name_year_rollups WHERE year = p_year
+        UNION
+        SELECT name FROM name_stats WHERE earliest_year <= p_year AND latest_year >= p_year
+    ) n;
+
+    IF affected IS NOT NULL THEN
+        PERFORM refresh_name_stats(affected);
+    END IF;
+END;
+$$;
+
+-- ============================================================================
+-- Backfill from already imported data
+-- ============================================================================
+
+INSERT INTO name_year_rollups (country_id, year, name, total_count, female_count, male_count, unknown_count)
+SELECT
+    country_id,
+    year,
+    name,
+    SUM(count),
+    SUM(CASE WHEN gender = 'F' THEN count ELSE 0 END),
+    SUM(CASE WHEN gender = 'M' THEN count ELSE 0 END),
+    SUM(CASE WHEN gender = 'U' THEN count ELSE 0 END)
+FROM names
+GROUP BY country_id, year, name;
+
+SELECT refresh_name_stats(NULL);
@@ -0,0 +1,36 @@
+-- Nomia - Data Version Migration
+-- Version: 011
+-- Description: Global data version bumped whenever datasets are imported or
+--              removed, used by the server to invalidate cached query results
+-- Date: 2026-10-18
+
+-- ============================================================================
+-- Table: data_version
+-- Purpose: Single-row counter of dataset changes
+-- ============================================================================
+
+CREATE TABLE data_version (
+    id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    version BIGINT NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+INSERT INTO data_version (id) VALUES (1);
+
+COMMENT ON TABLE data_version IS 'Global data version, bumped by the import tool after every import or removal';
+
+-- ============================================================================
+-- Function: bump_data_version
+-- Purpose: Increment the data version once the imported or removed data and
+-- everything derived from it is in place; returns the new version
+-- ============================================================================
+
+CREATE OR REPLACE FUNCTION bump_data_version()
+RETURNS BIGINT
+LANGUAGE sql
+AS $$
+    UPDATE data_version
+    SET version = version + 1, updated_at = NOW()
+    WHERE id = 1
+    RETURNING version;
+$$;